@@ -0,0 +1,36 @@
+// Code generated by ferry-gen. DO NOT EDIT.
+
+package v1
+
+import (
+	"context"
+
+	"github.com/damejeras/ferry"
+)
+
+// GreetServiceClient implements GreetService by calling remote ferry service.
+type GreetServiceClient struct {
+	client *ferry.Client
+}
+
+// NewGreetServiceClient creates GreetService client. Client's base URL must point to the path service is mounted on.
+func NewGreetServiceClient(client *ferry.Client) *GreetServiceClient {
+	return &GreetServiceClient{client: client}
+}
+
+var _ GreetService = (*GreetServiceClient)(nil)
+
+// HelloName calls remote GreetService.HelloName procedure.
+func (c *GreetServiceClient) HelloName(ctx context.Context, r *HelloNameRequest) (*HelloNameResponse, error) {
+	return ferry.Call[HelloNameRequest, HelloNameResponse](ctx, c.client, "/HelloName", r)
+}
+
+// HelloWorld calls remote GreetService.HelloWorld procedure.
+func (c *GreetServiceClient) HelloWorld(ctx context.Context, r *HelloWorldRequest) (*HelloWorldResponse, error) {
+	return ferry.Call[HelloWorldRequest, HelloWorldResponse](ctx, c.client, "/HelloWorld", r)
+}
+
+// StreamGreetings subscribes to remote GreetService.StreamGreetings stream.
+func (c *GreetServiceClient) StreamGreetings(ctx context.Context, r *StreamGreetingsRequest) (<-chan ferry.Event[Greeting], error) {
+	return ferry.Subscribe[StreamGreetingsRequest, Greeting](ctx, c.client, "/StreamGreetings", r)
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/damejeras/ferry"
 )
 
+//go:generate ferry-gen -type GreetService
+
 type GreetService interface {
 	HelloWorld(context.Context, *HelloWorldRequest) (*HelloWorldResponse, error)
 	HelloName(context.Context, *HelloNameRequest) (*HelloNameResponse, error)
@@ -19,7 +21,7 @@ type HelloWorldResponse struct {
 }
 
 type HelloNameRequest struct {
-	Name string `json:"name"`
+	Name string `json:"name" validate:"required,max=50"`
 }
 
 type HelloNameResponse struct {
@@ -27,7 +29,7 @@ type HelloNameResponse struct {
 }
 
 type StreamGreetingsRequest struct {
-	Name string `query:"name"`
+	Name string `query:"name" validate:"required,max=50"`
 }
 
 type Greeting struct {
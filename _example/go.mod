@@ -10,8 +10,10 @@ require (
 )
 
 require (
-	github.com/fatih/structtag v1.2.0 // indirect
-	github.com/mitchellh/reflectwalk v1.0.2 // indirect
+	github.com/fxamacker/cbor/v2 v2.5.0 // indirect
+	github.com/gorilla/websocket v1.5.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	google.golang.org/protobuf v1.34.0 // indirect
 )
 
 replace github.com/damejeras/ferry => ../.
@@ -3,7 +3,6 @@ package greet
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"strconv"
 	"time"
 
@@ -26,13 +25,6 @@ func (s *service) HelloName(_ context.Context, r *v1.HelloNameRequest) (*v1.Hell
 }
 
 func (s *service) StreamGreetings(ctx context.Context, r *v1.StreamGreetingsRequest) (<-chan ferry.Event[v1.Greeting], error) {
-	if r.Name == "" {
-		return nil, ferry.ClientError{
-			Code:    http.StatusBadRequest,
-			Message: "name is required",
-		}
-	}
-
 	stream := make(chan ferry.Event[v1.Greeting])
 
 	go func() {
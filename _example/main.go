@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"time"
 
 	"github.com/damejeras/ferry"
+	api "github.com/damejeras/ferry/example/api/v1"
 	"github.com/damejeras/ferry/example/internal/greet"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
@@ -15,13 +21,11 @@ func main() {
 	v1 := ferry.NewRouter(
 		// use default error handling but log errors with standard logger
 		ferry.WithErrorHandler(func(w http.ResponseWriter, r *http.Request, err error) {
-			switch err.(type) {
-			case ferry.ClientError:
-				ferry.DefaultErrorHandler(w, r, err)
-			default:
+			var coder ferry.StatusCoder
+			if !errors.As(err, &coder) {
 				log.Printf("unexpeced error: %v", err)
-				ferry.DefaultErrorHandler(w, r, err)
 			}
+			ferry.DefaultErrorHandler(w, r, err)
 		}),
 	)
 
@@ -36,27 +40,57 @@ func main() {
 	// This returns GreetService implementation from api/v1/greet.go. GreetService is the description of our API.
 	// The implementation is done in internal/greet/service.go
 	greetSvc := greet.NewService()
-	v1.Register(
-		// POST http://localhost:7777/api/v1/HelloWorld
-		// the endpoint path is reflected from function name.
-		ferry.Procedure(greetSvc.HelloWorld),
-		// POST http://localhost:7777/api/v1/HelloName
-		// Content-Type: application/json
-		// { "name": "Joe" }
-		// the endpoint path is reflected from function name.
-		ferry.Procedure(greetSvc.HelloName),
-		// GET http://localhost:7777/api/v1/StreamGreetings
-		// This will start streaming SSE events
-		// the endpoint path is reflected from function name.
-		ferry.Stream(greetSvc.StreamGreetings),
-	)
+	// RegisterService registers every method of GreetService interface, the endpoint paths are reflected from method names:
+	// POST http://localhost:7777/api/v1/GreetService/HelloWorld
+	// POST http://localhost:7777/api/v1/GreetService/HelloName
+	// Content-Type: application/json
+	// { "name": "Joe" }
+	// GET http://localhost:7777/api/v1/GreetService/StreamGreetings?name=Joe
+	// This will start streaming SSE events
+	// POST http://localhost:7777/api/v1/GreetService/StreamGreetings
+	// Accept: application/x-ndjson
+	// Content-Type: application/json
+	// { "name": "Joe" }
+	// This will start streaming newline delimited JSON
+	if err := ferry.RegisterService[api.GreetService](v1, greetSvc); err != nil {
+		log.Fatal(err)
+	}
 
 	router := chi.NewRouter()
 	router.Mount("/api/v1/GreetService", v1)
 	// Must be called last, because ferry.ServiceDiscovery is walking router paths.
 	router.Handle("/api/v1", ferry.ServiceDiscovery(router))
+	// OpenAPI document is created the same way, so it also has to be registered last.
+	router.Handle("/api/v1/openapi.json", ferry.OpenAPI(router, ferry.OpenAPIInfo{
+		Title:   "Greet API",
+		Version: "1.0.0",
+	}))
+	// TypeScript client for frontend applications.
+	router.Handle("/api/v1/client.ts", ferry.TypeScript(router))
+	// Protocol Buffers definition for teams using other languages.
+	router.Handle("/api/v1/ferry.proto", ferry.Proto(router, "greet.v1"))
 
-	if err := http.ListenAndServe(":7777", router); err != nil {
-		log.Fatal(err)
+	server := &http.Server{Addr: ":7777", Handler: router}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	}()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// http.Server.Shutdown waits for active requests, so open streams have to be finished first.
+	if err := v1.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown streams: %v", err)
+	}
+
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf("shutdown server: %v", err)
 	}
 }
@@ -0,0 +1,225 @@
+package ferry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// BidiStream will return Handler which can be used to register bidirectional stream served over WebSocket in Router.
+// Messages received from client are decoded from JSON and validated by `validate` tags before they are sent to in
+// channel. In channel is closed when client closes the connection. Messages are limited to 1MB just like request
+// bodies, larger messages close the connection. Events are sent to client as JSON messages:
+//
+//	{"id": "1", "event": "Greeting", "data": {"message": "Hello"}}
+//
+// Errors are sent as "error" events and the connection is closed. Just like Stream function, BidiStream function
+// MUST close its channel when context is cancelled.
+// Handler is named after the function, anonymous functions get generated names, so they should be named with Name option.
+func BidiStream[In any, Out any](fn func(ctx context.Context, in <-chan In) (<-chan Event[Out], error), options ...func(*meta)) Handler {
+	mt := handlerMeta(fn, reflect.TypeOf(new(In)).Elem(), reflect.TypeOf(new(Out)).Elem(), options)
+
+	return newBidiStream(mt, func(ctx context.Context, in reflect.Value) (reflect.Value, error) {
+		events, err := fn(ctx, in.Interface().(<-chan In))
+		return reflect.ValueOf(events), err
+	})
+}
+
+// eventMessage is the JSON message sent over WebSocket connection or as a line of NDJSON stream.
+type eventMessage struct {
+	ID    string          `json:"id,omitempty"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// newBidiStream creates bidirectional stream handler. Open receives receive-only channel of mt.request
+// and returns channel of Event with mt.response payload.
+func newBidiStream(mt meta, open func(ctx context.Context, in reflect.Value) (reflect.Value, error)) *bidiHandler {
+	mt.setDefaults(http.MethodGet)
+	payloadType := eventName(mt.response)
+	multiType := mt.response.Kind() == reflect.Interface
+	inType := reflect.ChanOf(reflect.BothDir, mt.request)
+	recvType := reflect.ChanOf(reflect.RecvDir, mt.request)
+
+	return &bidiHandler{
+		meta: mt,
+		builder: func(m *mux) http.HandlerFunc {
+			info := streamInfo(mt)
+			config := m.stream.merge(mt.stream)
+			upgrader := websocket.Upgrader{
+				Error: func(w http.ResponseWriter, r *http.Request, status int, reason error) {
+					m.errHandler(w, r, ClientError{Code: status, Message: reason.Error()})
+				},
+			}
+
+			return func(w http.ResponseWriter, r *http.Request) {
+				if !websocket.IsWebSocketUpgrade(r) {
+					m.errHandler(w, r, ClientError{
+						Code:    http.StatusBadRequest,
+						Message: "websocket upgrade expected",
+					})
+					return
+				}
+
+				if !m.streams.start() {
+					m.errHandler(w, r, errShuttingDown)
+					return
+				}
+				defer m.streams.finish()
+
+				ctx, cancel := context.WithCancel(createContext(w, r))
+				defer cancel()
+
+				in := reflect.MakeChan(inType, 0)
+				events, err := open(ctx, in.Convert(recvType))
+				if err != nil {
+					m.errHandler(w, r, err)
+					return
+				}
+
+				// stream function is expected to close the channel when stream is finished
+				closed := false
+				defer func() {
+					if !closed {
+						supervise(events, m.leakTimeout, m.leakPolicy, info)
+					}
+				}()
+
+				conn, err := upgrader.Upgrade(w, r, nil)
+				if err != nil {
+					// upgrader has already responded with an error, stream function is told that client has gone
+					cancel()
+					in.Close()
+					return
+				}
+				defer conn.Close()
+				// messages are limited the same way request bodies are, connection is closed if client exceeds the limit
+				conn.SetReadLimit(maxBodySize)
+
+				// client is considered gone if it does not respond to two pings in a row
+				conn.SetReadDeadline(time.Now().Add(2 * config.heartbeat))
+				conn.SetPongHandler(func(string) error {
+					return conn.SetReadDeadline(time.Now().Add(2 * config.heartbeat))
+				})
+
+				// errors of received messages are reported to writer, which owns the connection
+				failures := make(chan error, 1)
+				go func() {
+					defer cancel()
+					defer in.Close()
+
+					for {
+						_, data, err := conn.ReadMessage()
+						if err != nil {
+							return
+						}
+
+						msg := reflect.New(mt.request)
+						if err := json.Unmarshal(data, msg.Interface()); err != nil {
+							failures <- jsonError(err)
+							return
+						}
+						if err := validate(msg.Interface(), "json"); err != nil {
+							failures <- err
+							return
+						}
+
+						chosen, _, _ := reflect.Select([]reflect.SelectCase{
+							{Dir: reflect.SelectSend, Chan: in, Send: msg.Elem()},
+							{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+						})
+						if chosen == 1 {
+							return
+						}
+					}
+				}()
+
+				ping := time.NewTicker(config.heartbeat)
+				defer ping.Stop()
+
+				cases := []reflect.SelectCase{
+					{Dir: reflect.SelectRecv, Chan: events},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ping.C)},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(failures)},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.streams.done)},
+				}
+
+				if config.maxLifetime > 0 {
+					lifetime := time.NewTimer(config.maxLifetime)
+					defer lifetime.Stop()
+					cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(lifetime.C)})
+				}
+
+				for {
+					chosen, received, ok := reflect.Select(cases)
+
+					switch chosen {
+					case 0:
+						if !ok {
+							closed = true
+							closeConn(conn, websocket.CloseNormalClosure)
+							return
+						}
+
+						id, payload, err := received.Interface().(streamEvent).event()
+						if err != nil {
+							writeErrorMessage(conn, err)
+							return
+						}
+
+						data, err := json.Marshal(payload)
+						if err != nil {
+							writeErrorMessage(conn, fmt.Errorf("encode message: %w", err))
+							return
+						}
+
+						name := frameName(received.Interface().(streamEvent), payload, payloadType, multiType)
+						if err := conn.WriteJSON(eventMessage{ID: id, Event: name, Data: data}); err != nil {
+							return
+						}
+					case 1:
+						if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(config.heartbeat)); err != nil {
+							return
+						}
+					case 2:
+						// client has gone away or sent invalid message
+						select {
+						case err := <-failures:
+							writeErrorMessage(conn, err)
+						default:
+						}
+						return
+					case 3:
+						writeErrorMessage(conn, received.Interface().(error))
+						return
+					default:
+						// server is shutting down or stream reached its maximum lifetime, client is expected to reconnect
+						closeConn(conn, websocket.CloseGoingAway)
+						return
+					}
+				}
+			}
+		},
+	}
+}
+
+// writeErrorMessage sends error to client as "error" event and closes the connection.
+// Error is encoded the same way DefaultErrorHandler encodes it, unexpected errors are hidden from client.
+func writeErrorMessage(conn *websocket.Conn, err error) {
+	if err := conn.WriteJSON(eventMessage{Event: "error", Data: errorData(err)}); err != nil {
+		return
+	}
+
+	closeConn(conn, websocket.ClosePolicyViolation)
+}
+
+// closeConn sends close message with given code to client.
+func closeConn(conn *websocket.Conn, code int) {
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, ""), time.Now().Add(time.Second))
+}
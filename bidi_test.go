@@ -0,0 +1,224 @@
+package ferry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+type echoMessage struct {
+	Value string `json:"value" validate:"required"`
+}
+
+func (s testService) Echo(ctx context.Context, in <-chan echoMessage) (<-chan Event[testPayload], error) {
+	c := make(chan Event[testPayload])
+
+	go func() {
+		defer close(c)
+
+		for msg := range in {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- Event[testPayload]{ID: msg.Value, Payload: &testPayload{Value: msg.Value}}:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+func (s testService) RejectedEcho(ctx context.Context, in <-chan echoMessage) (<-chan Event[testPayload], error) {
+	return nil, PermissionDenied("forbidden")
+}
+
+func (s testService) Shout(ctx context.Context, in <-chan string) (<-chan Event[string], error) {
+	c := make(chan Event[string])
+
+	go func() {
+		defer close(c)
+
+		for msg := range in {
+			upper := strings.ToUpper(msg)
+			select {
+			case <-ctx.Done():
+				return
+			case c <- Event[string]{Payload: &upper}:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+func TestBidiStream(t *testing.T) {
+	svc := testService{}
+	router := NewRouter()
+	if err := router.Register(BidiStream(svc.Echo), BidiStream(svc.RejectedEcho)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	dial := func(t *testing.T, path string) *websocket.Conn {
+		conn, _, err := websocket.DefaultDialer.Dial(url+path, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		return conn
+	}
+
+	t.Run("echoes messages", func(t *testing.T) {
+		conn := dial(t, "/Echo")
+		defer conn.Close()
+
+		for _, value := range []string{"first", "second"} {
+			if err := conn.WriteJSON(echoMessage{Value: value}); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+
+			var msg eventMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				t.Fatalf("read: %v", err)
+			}
+
+			if msg.ID != value || msg.Event != "testPayload" || string(msg.Data) != `{"value":"`+value+`"}` {
+				t.Errorf("unexpected message, got %+v", msg)
+			}
+		}
+	})
+
+	t.Run("sends error event on invalid message", func(t *testing.T) {
+		conn := dial(t, "/Echo")
+		defer conn.Close()
+
+		if err := conn.WriteJSON(echoMessage{}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		var msg eventMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read: %v", err)
+		}
+
+		var body errorBody
+		if err := json.Unmarshal(msg.Data, &body); err != nil {
+			t.Fatalf("decode error: %v", err)
+		}
+
+		if msg.Event != "error" || body.Code != CodeInvalidArgument || len(body.Fields) != 1 {
+			t.Errorf("unexpected message, got %+v", msg)
+		}
+
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+			t.Errorf("expected connection to be closed, got %v", err)
+		}
+	})
+
+	t.Run("closes connection on oversized message", func(t *testing.T) {
+		conn := dial(t, "/Echo")
+		defer conn.Close()
+
+		value := strings.Repeat("a", maxBodySize)
+		if err := conn.WriteJSON(echoMessage{Value: value}); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+
+		if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+			t.Errorf("expected connection to be closed, got %v", err)
+		}
+	})
+
+	t.Run("responds with error before upgrade", func(t *testing.T) {
+		_, resp, err := websocket.DefaultDialer.Dial(url+"/RejectedEcho", nil)
+		if err == nil {
+			t.Fatal("expected dial to fail")
+		}
+
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("expected status %d, got %d", http.StatusForbidden, resp.StatusCode)
+		}
+	})
+
+	t.Run("rejects plain HTTP request", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/Echo", nil))
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
+
+func TestBidiStreamScalar(t *testing.T) {
+	svc := testService{}
+	router := NewRouter()
+	if err := router.Register(BidiStream(svc.Shout)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/Shout", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+
+	if err := conn.WriteJSON("hello"); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	var msg eventMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	if string(msg.Data) != `"HELLO"` {
+		t.Errorf("unexpected message, got %+v", msg)
+	}
+}
+
+func TestBidiStreamUpgradeFailure(t *testing.T) {
+	finished := make(chan struct{})
+	router := NewRouter()
+	router.Register(BidiStream(func(ctx context.Context, in <-chan echoMessage) (<-chan Event[testPayload], error) {
+		c := make(chan Event[testPayload])
+
+		go func() {
+			defer close(finished)
+			defer close(c)
+
+			for range in {
+			}
+		}()
+
+		return c, nil
+	}, Name("Drain")))
+
+	r := httptest.NewRequest(http.MethodGet, "/Drain", nil)
+	r.Header.Set("Connection", "Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, r)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Error("expected in channel to be closed when upgrade fails")
+	}
+}
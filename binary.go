@@ -0,0 +1,126 @@
+package ferry
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Binary is implemented by Procedure responses which are written to client as they are instead of being encoded.
+// Content is closed after it is written if it implements io.Closer.
+// Content-Length is sent when size of content is known and Range requests are supported if content is io.ReadSeeker.
+// Responses can also implement Filename() string method to be downloaded as attachments.
+type Binary interface {
+	Body() (contentType string, content io.Reader)
+}
+
+var binaryType = reflect.TypeOf((*Binary)(nil)).Elem()
+
+// isBinary reports whether values of type t or pointers to them implement Binary.
+func isBinary(t reflect.Type) bool {
+	return t.Implements(binaryType) || reflect.PtrTo(t).Implements(binaryType)
+}
+
+// Blob is a Procedure response which is written to client as it is.
+type Blob struct {
+	// ContentType defaults to "application/octet-stream".
+	ContentType string
+	// Content is written to client. Range requests are supported if it implements io.ReadSeeker.
+	Content io.Reader
+	// Size is sent as Content-Length if it is positive. Size of io.Seeker content is found out when Size is not set.
+	Size int64
+	// Filename is sent in Content-Disposition header, so browsers download content as attachment.
+	Filename string
+	// ModTime is sent as Last-Modified and used to answer conditional requests of io.ReadSeeker content.
+	ModTime time.Time
+}
+
+func (b *Blob) Body() (string, io.Reader) {
+	if b == nil {
+		return "application/octet-stream", nil
+	}
+	if b.ContentType == "" {
+		return "application/octet-stream", b.Content
+	}
+
+	return b.ContentType, b.Content
+}
+
+// writeBinary writes content of Binary response. Seekable content is served with http.ServeContent,
+// unless client accepts gzip encoding and does not request a range, in which case content is compressed.
+func writeBinary(w http.ResponseWriter, r *http.Request, status int, payload Binary) error {
+	contentType, content := payload.Body()
+	if closer, ok := content.(io.Closer); ok {
+		defer closer.Close()
+	}
+	if content == nil {
+		content = strings.NewReader("")
+	}
+
+	w.Header().Set("Content-Type", contentType)
+
+	var (
+		size    int64 = -1
+		modTime time.Time
+	)
+	switch p := payload.(type) {
+	case *Blob:
+		if p == nil {
+			break
+		}
+		if p.Size > 0 {
+			size = p.Size
+		}
+		modTime = p.ModTime
+		setFilename(w, p.Filename)
+	case interface{ Filename() string }:
+		setFilename(w, p.Filename())
+	}
+
+	if length, ok := content.(interface{ Len() int }); ok && size < 0 {
+		size = int64(length.Len())
+	}
+
+	gzipped := strings.Contains(r.Header.Get("Accept-Encoding"), "gzip")
+	if seeker, ok := content.(io.ReadSeeker); ok && status == http.StatusOK && (!gzipped || r.Header.Get("Range") != "") {
+		http.ServeContent(w, r, "", modTime, seeker)
+		return nil
+	}
+
+	if !modTime.IsZero() {
+		w.Header().Set("Last-Modified", modTime.UTC().Format(http.TimeFormat))
+	}
+
+	var out io.Writer = w
+	if gzipped {
+		w.Header().Set("Content-Encoding", "gzip")
+		gzw := gzip.NewWriter(w)
+		out = gzw
+		defer gzw.Close()
+	} else if size >= 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+
+	w.WriteHeader(status)
+
+	if _, err := io.Copy(out, content); err != nil {
+		return fmt.Errorf("write body: %w", err)
+	}
+
+	return nil
+}
+
+// setFilename sets Content-Disposition header, so content is downloaded as attachment with given name.
+func setFilename(w http.ResponseWriter, filename string) {
+	if filename == "" {
+		return
+	}
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+}
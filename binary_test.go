@@ -0,0 +1,112 @@
+package ferry
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type report struct {
+	content string
+}
+
+func (r *report) Body() (string, io.Reader) {
+	return "text/csv", io.NopCloser(strings.NewReader(r.content))
+}
+
+func (r *report) Filename() string { return "report.csv" }
+
+func (t testService) Download(ctx context.Context, r *jsonRequest) (*Blob, error) {
+	return &Blob{ContentType: "text/plain", Content: strings.NewReader(r.Value), Filename: "value.txt"}, nil
+}
+
+func (t testService) Report(ctx context.Context, r *jsonRequest) (*report, error) {
+	return &report{content: r.Value}, nil
+}
+
+func binaryRequest(path, value string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"value":"`+value+`"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	return r
+}
+
+func TestBinary(t *testing.T) {
+	svc := testService{}
+	router := NewRouter()
+	router.Register(Procedure(svc.Download), Procedure(svc.Report))
+
+	t.Run("writes blob", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, binaryRequest("/Download", "content"))
+
+		if rr.Code != http.StatusOK || rr.Body.String() != "content" {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+
+		expected := map[string]string{
+			"Content-Type":        "text/plain",
+			"Content-Length":      "7",
+			"Content-Disposition": `attachment; filename=value.txt`,
+			"Accept-Ranges":       "bytes",
+		}
+		for header, value := range expected {
+			if got := rr.Header().Get(header); got != value {
+				t.Errorf("expected %s header %q, got %q", header, value, got)
+			}
+		}
+	})
+
+	t.Run("supports range requests", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := binaryRequest("/Download", "content")
+		r.Header.Set("Range", "bytes=2-4")
+		r.Header.Set("Accept-Encoding", "gzip")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusPartialContent || rr.Body.String() != "nte" {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("compresses content", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := binaryRequest("/Report", "a,b")
+		r.Header.Set("Accept-Encoding", "gzip")
+		router.ServeHTTP(rr, r)
+
+		if encoding := rr.Header().Get("Content-Encoding"); encoding != "gzip" {
+			t.Fatalf("expected gzip encoding, got %q", encoding)
+		}
+
+		reader, err := gzip.NewReader(rr.Body)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		body, _ := io.ReadAll(reader)
+
+		if string(body) != "a,b" || rr.Header().Get("Content-Disposition") != "attachment; filename=report.csv" {
+			t.Errorf("unexpected response, got %s %v", body, rr.Header())
+		}
+	})
+
+	t.Run("describes binary response", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		OpenAPI(router, OpenAPIInfo{})(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+		var document map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &document); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		format := lookup(document, "paths", "/Report", "post", "responses", "200", "content", "application/octet-stream", "schema", "format")
+		if format != "binary" {
+			t.Errorf("expected binary response, got %v", lookup(document, "paths", "/Report", "post", "responses"))
+		}
+	})
+}
@@ -0,0 +1,358 @@
+package ferry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client calls Procedure and Stream endpoints served by ferry Router.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	header     http.Header
+}
+
+// NewClient creates Client for endpoints under baseURL.
+// Paths passed to Call and Subscribe are appended to baseURL.
+func NewClient(baseURL string, options ...func(*Client)) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+	}
+
+	for i := range options {
+		options[i](c)
+	}
+
+	return c
+}
+
+// WithHTTPClient sets http.Client used to make requests. http.DefaultClient is used by default.
+func WithHTTPClient(httpClient *http.Client) func(*Client) {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithHeader sets header which will be sent with every request.
+func WithHeader(key, value string) func(*Client) {
+	return func(c *Client) {
+		c.header.Set(key, value)
+	}
+}
+
+// Call invokes remote Procedure at given path and decodes its response.
+// If server responds with an error, Error with response status is returned. It can also be matched as ClientError.
+// If request does not pass validation, ValidationError is returned.
+func Call[Req any, Res any](ctx context.Context, c *Client, path string, req *Req) (*Res, error) {
+	if req == nil {
+		req = new(Req)
+	}
+
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := c.do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	res := new(Res)
+	if err := json.NewDecoder(resp.Body).Decode(res); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+
+	return res, nil
+}
+
+// Subscribe connects to remote Stream at given path. Request is sent as query parameters.
+// Returned channel is closed when context is cancelled or stream is finished.
+// If server terminates the stream with an error, connection fails or event can not be decoded, the last Event has Err set.
+// If Msg is an interface, events are decoded to its variants registered with RegisterVariants.
+// Variant is found by event name, so events which Type is overridden by server can not be decoded:
+// they are delivered with Type set and nil Payload.
+func Subscribe[Req any, Msg any](ctx context.Context, c *Client, path string, req *Req) (<-chan Event[Msg], error) {
+	target := c.baseURL + path
+	if req != nil {
+		if query := encodeQuery(req).Encode(); query != "" {
+			target += "?" + query
+		}
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan Event[Msg])
+	msgType := reflect.TypeOf(new(Msg)).Elem()
+
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		// fail delivers error as the last event, unless subscriber has cancelled the context
+		fail := func(err error) {
+			if ctx.Err() != nil {
+				return
+			}
+
+			select {
+			case events <- Event[Msg]{Err: err}:
+			case <-ctx.Done():
+			}
+		}
+
+		reader := bufio.NewReader(resp.Body)
+		for {
+			frame, err := readFrame(reader)
+			if errors.Is(err, io.EOF) {
+				// server has ended the stream
+				return
+			}
+			if err != nil {
+				fail(fmt.Errorf("read stream: %w", err))
+				return
+			}
+
+			// server terminated the stream with an error
+			if frame.event == "error" {
+				var body errorBody
+				if err := json.Unmarshal([]byte(frame.data), &body); err != nil || body.Message == "" {
+					body = newErrorBody(nil)
+				}
+				body.status = codeStatus(body.Code)
+
+				select {
+				case events <- Event[Msg]{ID: frame.id, Err: body.err()}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			// frames without data (such as keep-alive messages) carry no payload
+			if frame.event == "keep-alive" || frame.data == "" {
+				continue
+			}
+
+			payload := new(Msg)
+			if msgType.Kind() == reflect.Interface {
+				if variant, ok := variantByName(msgType, frame.event); ok {
+					value := reflect.New(variant)
+					if err := json.Unmarshal([]byte(frame.data), value.Interface()); err != nil {
+						fail(fmt.Errorf("decode %s event: %w", frame.event, err))
+						return
+					}
+					reflect.ValueOf(payload).Elem().Set(value.Elem())
+				} else {
+					// payload type of event with overridden Type is unknown
+					payload = nil
+				}
+			} else if err := json.Unmarshal([]byte(frame.data), payload); err != nil {
+				fail(fmt.Errorf("decode %s event: %w", frame.event, err))
+				return
+			}
+
+			select {
+			case events <- Event[Msg]{ID: frame.id, Type: frame.event, Payload: payload}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// do sends request and converts error responses to Error or ValidationError.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	for key := range c.header {
+		req.Header.Set(key, c.header.Get(key))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
+	}
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var body errorBody
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType == "application/problem+json" {
+		// errors of ProblemErrorHandler carry message in "detail" member
+		var p problem
+		if err := json.NewDecoder(resp.Body).Decode(&p); err == nil {
+			body = errorBody{Message: p.Detail, Code: p.Code, Details: p.Details, Fields: p.Fields}
+		}
+	} else if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		body = errorBody{}
+	}
+
+	if body.Message == "" {
+		body.Message = strings.ToLower(http.StatusText(resp.StatusCode))
+	}
+
+	if body.Code == "" {
+		body.Code = statusCode(resp.StatusCode)
+	}
+
+	body.status = resp.StatusCode
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		body.retryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return nil, body.err()
+}
+
+// sseFrame is a single message of text/event-stream.
+type sseFrame struct {
+	id    string
+	event string
+	data  string
+}
+
+// readFrame reads lines until blank line which ends the frame.
+func readFrame(reader *bufio.Reader) (sseFrame, error) {
+	var (
+		frame sseFrame
+		data  []string
+	)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return sseFrame{}, err
+		}
+
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			frame.data = strings.Join(data, "\n")
+			return frame, nil
+		}
+
+		// lines starting with colon are comments
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			frame.id = value
+		case "event":
+			frame.event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+}
+
+// encodeQuery maps struct properties with `query` tag to url.Values.
+// It is the reverse of decodeQuery: nil pointers are skipped, slices are sent as repeated keys
+// and properties of nested structs are sent with "parent.child" keys.
+func encodeQuery(v interface{}) url.Values {
+	values := make(url.Values)
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if !value.IsValid() {
+		return values
+	}
+
+	for _, field := range bindingFields(value.Type(), "query") {
+		property, ok := propertyByIndex(value, field.index)
+		if !ok {
+			continue
+		}
+
+		for _, item := range encodeValues(property) {
+			values.Add(field.key, item)
+		}
+	}
+
+	return values
+}
+
+// propertyByIndex returns nested property of struct, or false if one of the structs along the way is nil.
+func propertyByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// encodeValues converts property to strings which are bound back to it by setValues.
+func encodeValues(v reflect.Value) []string {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if v.Type() == durationType {
+		return []string{time.Duration(v.Int()).String()}
+	}
+
+	if isText(v.Type()) {
+		marshaler, ok := v.Interface().(encoding.TextMarshaler)
+		if !ok && v.CanAddr() {
+			marshaler, ok = v.Addr().Interface().(encoding.TextMarshaler)
+		}
+		if ok {
+			if text, err := marshaler.MarshalText(); err == nil {
+				return []string{string(text)}
+			}
+		}
+	}
+
+	if v.Kind() == reflect.Slice {
+		values := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, encodeValues(v.Index(i))...)
+		}
+		return values
+	}
+
+	return []string{fmt.Sprint(v.Interface())}
+}
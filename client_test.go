@@ -0,0 +1,258 @@
+package ferry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func (t testService) TestProcedureWithError(ctx context.Context, r *jsonRequest) (*testPayload, error) {
+	if r.Value == "exhausted" {
+		return nil, ResourceExhausted(r.Value, time.Minute, "quota")
+	}
+
+	return nil, ClientError{Code: http.StatusConflict, Message: r.Value}
+}
+
+func (t testService) StreamRequest(ctx context.Context, r *bindingRequest) (<-chan Event[bindingRequest], error) {
+	c := make(chan Event[bindingRequest], 1)
+	c <- Event[bindingRequest]{Payload: r}
+	close(c)
+
+	return c, nil
+}
+
+func TestClient(t *testing.T) {
+	svc := testService{}
+	router := NewRouter()
+	router.Register(
+		Procedure(svc.TestProcedureWithParams),
+		Procedure(svc.TestProcedureWithError),
+		Stream(svc.StreamOneEvent),
+		Stream(svc.FailingStream),
+		Stream(svc.StreamRequest),
+	)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	t.Run("calls procedure", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		res, err := Call[jsonRequest, testPayload](ctx, client, "/TestProcedureWithParams", &jsonRequest{Value: "test_data"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if res.Value != "test_data" {
+			t.Errorf("unexpected response, got %+v", res)
+		}
+	})
+
+	t.Run("decodes client error", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := Call[jsonRequest, testPayload](ctx, client, "/TestProcedureWithError", &jsonRequest{Value: "conflict"})
+
+		var clientErr ClientError
+		if !errors.As(err, &clientErr) {
+			t.Fatalf("expected ClientError, got %v", err)
+		}
+
+		if clientErr.Code != http.StatusConflict || clientErr.Message != "conflict" {
+			t.Errorf("unexpected error, got %+v", clientErr)
+		}
+	})
+
+	t.Run("decodes error", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := Call[jsonRequest, testPayload](ctx, client, "/TestProcedureWithError", &jsonRequest{Value: "exhausted"})
+
+		var ferryErr Error
+		if !errors.As(err, &ferryErr) {
+			t.Fatalf("expected Error, got %v", err)
+		}
+
+		if ferryErr.Status != http.StatusTooManyRequests || ferryErr.Code != CodeResourceExhausted ||
+			ferryErr.RetryAfter != time.Minute || len(ferryErr.Details) != 1 {
+			t.Errorf("unexpected error, got %+v", ferryErr)
+		}
+	})
+
+	t.Run("decodes not found error", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_, err := Call[empty, empty](ctx, client, "/Missing", nil)
+
+		var clientErr ClientError
+		if !errors.As(err, &clientErr) || clientErr.Code != http.StatusNotFound {
+			t.Errorf("expected not found error, got %v", err)
+		}
+	})
+
+	t.Run("subscribes to stream", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		events, err := Subscribe[queryRequest, testPayload](ctx, client, "/StreamOneEvent", &queryRequest{Value: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		received := make([]Event[testPayload], 0)
+		for event := range events {
+			received = append(received, event)
+		}
+
+		if len(received) != 1 {
+			t.Fatalf("expected one event, got %d", len(received))
+		}
+
+		if received[0].ID != "1" || received[0].Payload.Value != "test" {
+			t.Errorf("unexpected event, got %+v", received[0])
+		}
+	})
+
+	t.Run("sends request as query", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		offset, optional := 0, false
+		request := bindingRequest{
+			Paging:   Paging{Page: 2},
+			Limit:    5,
+			Offset:   &offset,
+			IDs:      []int64{1, 2},
+			Since:    time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC),
+			Timeout:  90 * time.Second,
+			Address:  net.ParseIP("127.0.0.1"),
+			Filter:   queryFilter{Name: "john", Tags: []string{"a", "b"}},
+			Sort:     &queryFilter{Name: "date"},
+			Optional: &optional,
+		}
+
+		events, err := Subscribe[bindingRequest, bindingRequest](ctx, client, "/StreamRequest", &request)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		event, ok := <-events
+		if !ok || event.Err != nil {
+			t.Fatalf("unexpected event, got %+v", event)
+		}
+
+		if !reflect.DeepEqual(*event.Payload, request) {
+			t.Errorf("unexpected request, got %+v", *event.Payload)
+		}
+	})
+
+	t.Run("receives stream error", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		events, err := Subscribe[queryRequest, testPayload](ctx, client, "/FailingStream", &queryRequest{Value: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		received := make([]Event[testPayload], 0)
+		for event := range events {
+			received = append(received, event)
+		}
+
+		if len(received) != 2 {
+			t.Fatalf("expected two events, got %d", len(received))
+		}
+
+		var ferryErr Error
+		if !errors.As(received[1].Err, &ferryErr) || ferryErr.Status != http.StatusNotFound || ferryErr.Code != CodeNotFound {
+			t.Errorf("unexpected error, got %v", received[1].Err)
+		}
+	})
+}
+
+func TestClientProblemErrors(t *testing.T) {
+	svc := testService{}
+	router := NewRouter(WithErrorHandler(ProblemErrorHandler))
+	router.Register(Procedure(svc.TestProcedureWithError))
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	client := NewClient(server.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	_, err := Call[jsonRequest, testPayload](ctx, client, "/TestProcedureWithError", &jsonRequest{Value: "exhausted"})
+
+	var ferryErr Error
+	if !errors.As(err, &ferryErr) {
+		t.Fatalf("expected Error, got %v", err)
+	}
+
+	if ferryErr.Message != "exhausted" || ferryErr.Code != CodeResourceExhausted ||
+		ferryErr.RetryAfter != time.Minute || len(ferryErr.Details) != 1 {
+		t.Errorf("unexpected error, got %+v", ferryErr)
+	}
+}
+
+func TestSubscribeFailures(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name: "reports undecodable event",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, "id: 1\nevent: testPayload\ndata: {\"value\":\n\n")
+			},
+		},
+		{
+			name: "reports broken connection",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				// connection is closed before announced body is written
+				w.Header().Set("Content-Length", "1000")
+				io.WriteString(w, "id: 1\nevent: testPayload\n")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			events, err := Subscribe[empty, testPayload](ctx, NewClient(server.URL), "/", nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			event, ok := <-events
+			if !ok || event.Err == nil {
+				t.Errorf("expected event with error, got %+v", event)
+			}
+
+			if _, ok := <-events; ok {
+				t.Error("expected channel to be closed")
+			}
+		})
+	}
+}
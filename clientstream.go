@@ -0,0 +1,179 @@
+package ferry
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// ClientStream will return Handler which can be used to register client-streaming procedure in Router.
+// Request body is decoded incrementally as newline delimited JSON ("application/x-ndjson" or JSON Lines),
+// each record is validated and sent to in channel, which is closed when body is read.
+// Records are sent only as fast as function receives them, so body is never buffered in memory.
+// If record can not be decoded, error with its line number is returned to client. Context is cancelled before
+// in channel is closed when body can not be read completely, so function has to check ctx.Err() after the channel
+// is closed to tell incomplete input from the end of body.
+// Handler is named after the function, anonymous functions get generated names, so they should be named with Name option.
+func ClientStream[Msg any, Res any](fn func(ctx context.Context, in <-chan Msg) (*Res, error), options ...func(*meta)) Handler {
+	mt := handlerMeta(fn, reflect.TypeOf(new(Msg)).Elem(), reflect.TypeOf(new(Res)).Elem(), options)
+
+	return newClientStream(mt, func(ctx context.Context, in reflect.Value) (interface{}, error) {
+		return fn(ctx, in.Interface().(<-chan Msg))
+	})
+}
+
+// ndjsonTypes are media types of newline delimited JSON accepted by ClientStream.
+var ndjsonTypes = map[string]bool{
+	"application/x-ndjson":    true,
+	"application/jsonl":       true,
+	"application/x-jsonlines": true,
+}
+
+// newClientStream creates client-streaming procedure handler. Call receives receive-only channel of mt.request.
+func newClientStream(mt meta, call func(ctx context.Context, in reflect.Value) (interface{}, error)) *clientStreamHandler {
+	mt.setDefaults(http.MethodPost)
+	inType := reflect.ChanOf(reflect.BothDir, mt.request)
+	recvType := reflect.ChanOf(reflect.RecvDir, mt.request)
+
+	return &clientStreamHandler{
+		meta: mt,
+		builder: func(m *mux) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+				if err != nil || !ndjsonTypes[mediaType] {
+					m.errHandler(w, r, ClientError{
+						Code:    http.StatusUnsupportedMediaType,
+						Message: "application/x-ndjson content-type expected",
+					})
+					return
+				}
+				defer r.Body.Close()
+
+				ctx, cancel := context.WithCancel(createContext(w, r))
+				defer cancel()
+
+				in := reflect.MakeChan(inType, 0)
+
+				type result struct {
+					response interface{}
+					err      error
+				}
+				results := make(chan result, 1)
+				go func() {
+					response, err := call(ctx, in.Convert(recvType))
+					results <- result{response, err}
+				}()
+
+				// function may return before the whole body is read
+				var (
+					res      result
+					returned bool
+				)
+
+				decodeErr := readRecords(r, mt.request, func(record reflect.Value) bool {
+					chosen, received, _ := reflect.Select([]reflect.SelectCase{
+						{Dir: reflect.SelectSend, Chan: in, Send: record},
+						{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(results)},
+					})
+					if chosen == 1 {
+						res, returned = received.Interface().(result), true
+						return false
+					}
+
+					return true
+				})
+
+				if decodeErr != nil {
+					// stop function, its result is not needed anymore. Context is cancelled before the channel
+					// is closed, so function can tell incomplete input from the end of body.
+					cancel()
+				}
+				in.Close()
+				if !returned {
+					res = <-results
+				}
+
+				switch {
+				case decodeErr != nil:
+					m.errHandler(w, r, decodeErr)
+				case res.err != nil:
+					m.errHandler(w, r, res.err)
+				default:
+					if err := Encode(w, r, http.StatusOK, res.response); err != nil {
+						m.errHandler(w, r, err)
+					}
+				}
+			}
+		},
+	}
+}
+
+// readRecords decodes request body line by line and passes records of given type to send until it returns false.
+// Empty lines are skipped. Single record can not be larger than 1 MiB.
+func readRecords(r *http.Request, t reflect.Type, send func(reflect.Value) bool) error {
+	scanner := bufio.NewScanner(r.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxBodySize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		record := reflect.New(t)
+		if err := json.Unmarshal(scanner.Bytes(), record.Interface()); err != nil {
+			return recordError(line, jsonError(err))
+		}
+
+		if err := validate(record.Interface(), "json"); err != nil {
+			return recordError(line, err)
+		}
+
+		if !send(record.Elem()) {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return Error{
+				Status:  http.StatusRequestEntityTooLarge,
+				Code:    statusCode(http.StatusRequestEntityTooLarge),
+				Message: fmt.Sprintf("line %d: record too large", line+1),
+				Details: []interface{}{recordDetail{Line: line + 1}},
+			}
+		}
+		return fmt.Errorf("read request body: %w", err)
+	}
+
+	return nil
+}
+
+// recordError prefixes message of client errors with line number of the record.
+func recordError(line int, err error) error {
+	var validationErr ValidationError
+	if errors.As(err, &validationErr) {
+		validationErr.Message = fmt.Sprintf("line %d: %s", line, validationErr.Message)
+		return validationErr
+	}
+
+	var ferryErr Error
+	if errors.As(err, &ferryErr) {
+		ferryErr.Message = fmt.Sprintf("line %d: %s", line, ferryErr.Message)
+		ferryErr.Details = append([]interface{}{recordDetail{Line: line}}, ferryErr.Details...)
+		return ferryErr
+	}
+
+	return err
+}
+
+// recordDetail points client to the invalid record of request body.
+type recordDetail struct {
+	Line int `json:"line"`
+}
@@ -0,0 +1,182 @@
+package ferry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type importResult struct {
+	Count  int      `json:"count"`
+	Values []string `json:"values"`
+}
+
+func (s testService) Import(ctx context.Context, in <-chan echoMessage) (*importResult, error) {
+	res := &importResult{}
+	for msg := range in {
+		if msg.Value == "stop" {
+			return res, nil
+		}
+
+		res.Count++
+		res.Values = append(res.Values, msg.Value)
+	}
+
+	return res, nil
+}
+
+func (s testService) Lines(ctx context.Context, in <-chan string) (*importResult, error) {
+	res := &importResult{}
+	for line := range in {
+		res.Count++
+		res.Values = append(res.Values, line)
+	}
+
+	return res, nil
+}
+
+func TestClientStream(t *testing.T) {
+	svc := testService{}
+	router := NewRouter()
+	if err := router.Register(ClientStream(svc.Import)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+		response    string
+	}{
+		{
+			name:        "receives records",
+			contentType: "application/x-ndjson",
+			body:        "{\"value\":\"first\"}\n\n{\"value\":\"second\"}\n{\"value\":\"third\"}",
+			status:      http.StatusOK,
+			response:    `{"count":3,"values":["first","second","third"]}`,
+		},
+		{
+			name:        "responds when function returns early",
+			contentType: "application/jsonl; charset=utf-8",
+			body:        "{\"value\":\"first\"}\n{\"value\":\"stop\"}\n{\"value\":\"third\"}\n",
+			status:      http.StatusOK,
+			response:    `{"count":1,"values":["first"]}`,
+		},
+		{
+			name:        "reports line of malformed record",
+			contentType: "application/x-ndjson",
+			body:        "{\"value\":\"first\"}\n{\"value\":}\n",
+			status:      http.StatusBadRequest,
+			response:    `{"error":"line 2: malformed request body at offset 10: invalid character '}' looking for beginning of value","code":"invalid_argument","details":[{"line":2},{"offset":10}]}`,
+		},
+		{
+			name:        "reports line of invalid record",
+			contentType: "application/x-ndjson",
+			body:        "{\"value\":\"first\"}\n{\"value\":\"second\"}\n{}\n",
+			status:      http.StatusUnprocessableEntity,
+			response:    `{"error":"line 3: validation failed","code":"invalid_argument","fields":[{"field":"value","rule":"required","message":"is required"}]}`,
+		},
+		{
+			name:        "rejects too large record",
+			contentType: "application/x-ndjson",
+			body:        "{\"value\":\"first\"}\n{\"value\":\"" + strings.Repeat("a", maxBodySize) + "\"}\n",
+			status:      http.StatusRequestEntityTooLarge,
+			response:    `{"error":"line 2: record too large","code":"request_entity_too_large","details":[{"line":2}]}`,
+		},
+		{
+			name:        "rejects unsupported content type",
+			contentType: "application/json",
+			body:        `{"value":"first"}`,
+			status:      http.StatusUnsupportedMediaType,
+			response:    `{"error":"application/x-ndjson content-type expected","code":"unsupported_media_type"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/Import", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, r)
+
+			if rr.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, rr.Code)
+			}
+
+			if body := rr.Body.String(); body != tt.response {
+				t.Errorf("unexpected response, got %s", body)
+			}
+		})
+	}
+
+	t.Run("describes request body", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		OpenAPI(router, OpenAPIInfo{})(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		var document map[string]interface{}
+		if err := json.NewDecoder(rr.Body).Decode(&document); err != nil {
+			t.Fatalf("decode document: %v", err)
+		}
+
+		if ref := lookup(document, "paths", "/Import", "post", "requestBody", "content", "application/x-ndjson", "schema", "$ref"); ref != "#/components/schemas/echoMessage" {
+			t.Errorf("unexpected request schema, got %v", ref)
+		}
+	})
+}
+
+func TestClientStreamScalar(t *testing.T) {
+	svc := testService{}
+	router := NewRouter()
+	if err := router.Register(ClientStream(svc.Lines)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/Lines", strings.NewReader("\"first\"\n\"second\"\n"))
+	r.Header.Set("Content-Type", "application/x-ndjson")
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, r)
+
+	if body := rr.Body.String(); rr.Code != http.StatusOK || body != `{"count":2,"values":["first","second"]}` {
+		t.Errorf("unexpected response, got %d %s", rr.Code, body)
+	}
+}
+
+func TestClientStreamIncompleteInput(t *testing.T) {
+	incomplete := make(chan bool, 1)
+	router := NewRouter()
+	router.Register(ClientStream(func(ctx context.Context, in <-chan string) (*importResult, error) {
+		for range in {
+		}
+		incomplete <- ctx.Err() != nil
+
+		return &importResult{}, nil
+	}, Name("Incomplete")))
+
+	tests := []struct {
+		name     string
+		body     string
+		expected bool
+	}{
+		{name: "complete body", body: "\"first\"\n\"second\"\n", expected: false},
+		{name: "malformed record", body: "\"first\"\nsecond\n", expected: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/Incomplete", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/x-ndjson")
+			router.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got := <-incomplete; got != tt.expected {
+				t.Errorf("expected cancelled context %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const ferryPath = "github.com/damejeras/ferry"
+
+// service is an interface which methods can be served with ferry.
+type service struct {
+	Name    string
+	Methods []method
+}
+
+// method is a Procedure or Stream of the service.
+type method struct {
+	Name     string
+	Stream   bool
+	Request  types.Type
+	Response types.Type
+}
+
+// findServices returns interfaces declared in package which methods are ferry handlers.
+// If names are provided, only these interfaces are returned and all of them must be valid services.
+func findServices(pkg *types.Package, names []string) ([]service, error) {
+	explicit := len(names) > 0
+	if !explicit {
+		names = pkg.Scope().Names()
+	}
+
+	services := make([]service, 0)
+	for _, name := range names {
+		svc, err := inspect(pkg, name)
+		if err != nil {
+			if explicit {
+				return nil, err
+			}
+			continue
+		}
+
+		services = append(services, svc)
+	}
+
+	return services, nil
+}
+
+// inspect checks if named type is an interface with Procedure and Stream methods only.
+func inspect(pkg *types.Package, name string) (service, error) {
+	obj, ok := pkg.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return service{}, fmt.Errorf("type %q not found in %s", name, pkg.Path())
+	}
+
+	iface, ok := obj.Type().Underlying().(*types.Interface)
+	if !ok || iface.NumMethods() == 0 {
+		return service{}, fmt.Errorf("type %q is not an interface with methods", name)
+	}
+
+	svc := service{Name: name}
+	for i := 0; i < iface.NumMethods(); i++ {
+		m, err := classify(iface.Method(i))
+		if err != nil {
+			return service{}, fmt.Errorf("%s.%w", name, err)
+		}
+		svc.Methods = append(svc.Methods, m)
+	}
+
+	return svc, nil
+}
+
+// classify checks if function signature matches Procedure or Stream.
+func classify(fn *types.Func) (method, error) {
+	sig := fn.Type().(*types.Signature)
+	if sig.Params().Len() != 2 || sig.Results().Len() != 2 {
+		return method{}, fmt.Errorf("%s: expected two parameters and two results", fn.Name())
+	}
+
+	if !isNamed(sig.Params().At(0).Type(), "context", "Context") {
+		return method{}, fmt.Errorf("%s: first parameter must be context.Context", fn.Name())
+	}
+
+	if !isNamed(sig.Results().At(1).Type(), "", "error") {
+		return method{}, fmt.Errorf("%s: last result must be error", fn.Name())
+	}
+
+	request, ok := sig.Params().At(1).Type().(*types.Pointer)
+	if !ok {
+		return method{}, fmt.Errorf("%s: request must be a pointer", fn.Name())
+	}
+
+	switch res := sig.Results().At(0).Type().(type) {
+	case *types.Pointer:
+		return method{Name: fn.Name(), Request: request.Elem(), Response: res.Elem()}, nil
+	case *types.Chan:
+		event, ok := res.Elem().(*types.Named)
+		if res.Dir() != types.RecvOnly || !ok || !isNamed(event, ferryPath, "Event") {
+			return method{}, fmt.Errorf("%s: stream must return <-chan ferry.Event", fn.Name())
+		}
+		return method{Name: fn.Name(), Stream: true, Request: request.Elem(), Response: event.TypeArgs().At(0)}, nil
+	default:
+		return method{}, fmt.Errorf("%s: response must be a pointer or <-chan ferry.Event", fn.Name())
+	}
+}
+
+// isNamed reports whether type is named type with given package path and name.
+// Empty path matches predeclared types.
+func isNamed(t types.Type, path, name string) bool {
+	named, ok := t.(*types.Named)
+	if !ok || named.Obj().Name() != name {
+		return false
+	}
+
+	if named.Obj().Pkg() == nil {
+		return path == ""
+	}
+
+	return named.Obj().Pkg().Path() == path
+}
+
+// generate renders client source code for services into package pkg.
+func generate(pkg *types.Package, services []service) ([]byte, error) {
+	imports := map[string]string{"context": "context", ferryPath: "ferry"}
+	qualifier := func(p *types.Package) string {
+		if p == pkg {
+			return ""
+		}
+		imports[p.Path()] = p.Name()
+		return p.Name()
+	}
+
+	funcs := template.FuncMap{
+		"type": func(t types.Type) string { return types.TypeString(t, qualifier) },
+	}
+
+	// render body first, so the qualifier collects all imports
+	var body bytes.Buffer
+	if err := template.Must(template.New("client").Funcs(funcs).Parse(clientTemplate)).Execute(&body, services); err != nil {
+		return nil, fmt.Errorf("render client: %w", err)
+	}
+
+	// standard library imports are grouped separately
+	var std, external []string
+	for path := range imports {
+		switch {
+		case path == pkg.Path():
+			continue
+		case strings.Contains(strings.Split(path, "/")[0], "."):
+			external = append(external, path)
+		default:
+			std = append(std, path)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(external)
+
+	var source bytes.Buffer
+	fmt.Fprintf(&source, "// Code generated by ferry-gen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg.Name())
+	for _, group := range [][]string{std, external} {
+		for _, path := range group {
+			fmt.Fprintf(&source, "\t%q\n", path)
+		}
+		fmt.Fprintf(&source, "\n")
+	}
+	fmt.Fprintf(&source, ")\n")
+	source.Write(body.Bytes())
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format source: %w", err)
+	}
+
+	return formatted, nil
+}
+
+const clientTemplate = `
+{{ range . }}
+// {{ .Name }}Client implements {{ .Name }} by calling remote ferry service.
+type {{ .Name }}Client struct {
+	client *ferry.Client
+}
+
+// New{{ .Name }}Client creates {{ .Name }} client. Client's base URL must point to the path service is mounted on.
+func New{{ .Name }}Client(client *ferry.Client) *{{ .Name }}Client {
+	return &{{ .Name }}Client{client: client}
+}
+
+var _ {{ .Name }} = (*{{ .Name }}Client)(nil)
+{{ $svc := .Name }}
+{{ range .Methods }}
+{{- if .Stream }}
+// {{ .Name }} subscribes to remote {{ $svc }}.{{ .Name }} stream.
+func (c *{{ $svc }}Client) {{ .Name }}(ctx context.Context, r *{{ type .Request }}) (<-chan ferry.Event[{{ type .Response }}], error) {
+	return ferry.Subscribe[{{ type .Request }}, {{ type .Response }}](ctx, c.client, "/{{ .Name }}", r)
+}
+{{ else }}
+// {{ .Name }} calls remote {{ $svc }}.{{ .Name }} procedure.
+func (c *{{ $svc }}Client) {{ .Name }}(ctx context.Context, r *{{ type .Request }}) (*{{ type .Response }}, error) {
+	return ferry.Call[{{ type .Request }}, {{ type .Response }}](ctx, c.client, "/{{ .Name }}", r)
+}
+{{ end }}
+{{- end }}
+{{- end }}
+`
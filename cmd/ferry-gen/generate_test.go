@@ -0,0 +1,126 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+const ferryStub = `package ferry
+
+type Event[P any] struct {
+	ID      string
+	Payload *P
+}
+
+type Client struct{}
+`
+
+const apiSource = `package api
+
+import (
+	"context"
+
+	"github.com/damejeras/ferry"
+)
+
+type GreetService interface {
+	Hello(context.Context, *HelloRequest) (*HelloResponse, error)
+	Greetings(context.Context, *HelloRequest) (<-chan ferry.Event[HelloResponse], error)
+}
+
+type InvalidService interface {
+	Hello(context.Context, HelloRequest) (*HelloResponse, error)
+}
+
+type HelloRequest struct{}
+
+type HelloResponse struct{}
+`
+
+func TestGenerate(t *testing.T) {
+	pkg := check(t, "example.com/api", apiSource, map[string]*types.Package{
+		"github.com/damejeras/ferry": check(t, "github.com/damejeras/ferry", ferryStub, nil),
+	})
+
+	t.Run("finds valid services", func(t *testing.T) {
+		services, err := findServices(pkg, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if len(services) != 1 || services[0].Name != "GreetService" {
+			t.Fatalf("unexpected services, got %+v", services)
+		}
+
+		if len(services[0].Methods) != 2 || !services[0].Methods[0].Stream || services[0].Methods[1].Stream {
+			t.Errorf("unexpected methods, got %+v", services[0].Methods)
+		}
+	})
+
+	t.Run("rejects explicitly requested invalid service", func(t *testing.T) {
+		if _, err := findServices(pkg, []string{"InvalidService"}); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("generates client", func(t *testing.T) {
+		services, err := findServices(pkg, []string{"GreetService"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		source, err := generate(pkg, services)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []string{
+			"func NewGreetServiceClient(client *ferry.Client) *GreetServiceClient {",
+			"return ferry.Call[HelloRequest, HelloResponse](ctx, c.client, \"/Hello\", r)",
+			"return ferry.Subscribe[HelloRequest, HelloResponse](ctx, c.client, \"/Greetings\", r)",
+		}
+		for _, line := range expected {
+			if !strings.Contains(string(source), line) {
+				t.Errorf("expected generated source to contain %q, got:\n%s", line, source)
+			}
+		}
+
+		check(t, "example.com/api", apiSource+strings.SplitN(string(source), ")\n", 2)[1], map[string]*types.Package{
+			"github.com/damejeras/ferry": check(t, "github.com/damejeras/ferry", ferryStub+"func Call[Req, Res any](_ any, _ *Client, _ string, _ *Req) (*Res, error) { return nil, nil }\nfunc Subscribe[Req, Msg any](_ any, _ *Client, _ string, _ *Req) (<-chan Event[Msg], error) { return nil, nil }\n", nil),
+		})
+	})
+}
+
+// check type-checks single file package. Imports are resolved from deps, falling back to standard library.
+func check(t *testing.T, path, source string, deps map[string]*types.Package) *types.Package {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "source.go", source, 0)
+	if err != nil {
+		t.Fatalf("parse %s: %v", path, err)
+	}
+
+	conf := types.Config{Importer: importerFunc(func(importPath string) (*types.Package, error) {
+		if pkg, ok := deps[importPath]; ok {
+			return pkg, nil
+		}
+		return importer.Default().Import(importPath)
+	})}
+
+	pkg, err := conf.Check(path, fset, []*ast.File{file}, nil)
+	if err != nil {
+		t.Fatalf("check %s: %v", path, err)
+	}
+
+	return pkg
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) { return f(path) }
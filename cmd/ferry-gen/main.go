@@ -0,0 +1,102 @@
+// Command ferry-gen generates Go clients for API interfaces served with ferry.
+//
+// It loads a package, finds interfaces whose methods match Procedure
+// (func(context.Context, *Req) (*Res, error)) or Stream
+// (func(context.Context, *Req) (<-chan ferry.Event[Msg], error)) signatures
+// and generates client structs implementing these interfaces over HTTP.
+//
+// Usage:
+//
+//	ferry-gen [-type GreetService] [-output ferry_client.go] [package]
+//
+// It is intended to be used with go:generate directive placed in the API package:
+//
+//	//go:generate go run github.com/damejeras/ferry/cmd/ferry-gen
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of interface names, all matching interfaces are used by default")
+	output := flag.String("output", "ferry_client.go", "output file name, relative paths are resolved against package directory")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: ferry-gen [flags] [package]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	pattern := "."
+	if flag.NArg() > 0 {
+		pattern = flag.Arg(0)
+	}
+
+	if err := run(pattern, *typeNames, *output); err != nil {
+		fmt.Fprintf(os.Stderr, "ferry-gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(pattern, typeNames, output string) error {
+	pkg, err := load(pattern, filepath.Base(output))
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	if typeNames != "" {
+		names = strings.Split(typeNames, ",")
+	}
+
+	services, err := findServices(pkg.Types, names)
+	if err != nil {
+		return err
+	}
+
+	if len(services) == 0 {
+		return fmt.Errorf("no ferry service interfaces found in %s", pkg.PkgPath)
+	}
+
+	source, err := generate(pkg.Types, services)
+	if err != nil {
+		return err
+	}
+
+	if !filepath.IsAbs(output) && len(pkg.GoFiles) > 0 {
+		output = filepath.Join(filepath.Dir(pkg.GoFiles[0]), output)
+	}
+
+	return os.WriteFile(output, source, 0o644)
+}
+
+// load loads single package matching pattern. Type errors in previously
+// generated output are ignored, because the output is going to be replaced.
+func load(pattern, output string) (*packages.Package, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedTypes | packages.NeedSyntax | packages.NeedTypesInfo,
+	}, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("load package: %w", err)
+	}
+
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("pattern %q matched %d packages, expected one", pattern, len(pkgs))
+	}
+
+	pkg := pkgs[0]
+	for _, pkgErr := range pkg.Errors {
+		if strings.Contains(pkgErr.Pos, output) {
+			continue
+		}
+		return nil, fmt.Errorf("load package: %v", pkgErr)
+	}
+
+	return pkg, nil
+}
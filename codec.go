@@ -0,0 +1,255 @@
+package ferry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/fxamacker/cbor/v2"
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec encodes and decodes request and response bodies of its media type.
+// Codecs are registered in Router with WithCodecs option.
+type Codec interface {
+	// ContentType returns media type of the codec, such as "application/json".
+	ContentType() string
+	Decode(r io.Reader, v any) error
+	Encode(w io.Writer, v any) error
+}
+
+var (
+	// JSONCodec encodes bodies as JSON. It is the default codec of the Router.
+	JSONCodec Codec = jsonCodec{}
+	// CBORCodec encodes bodies as CBOR (RFC 8949). Struct fields are named after their `json` tags.
+	CBORCodec Codec = cborCodec{}
+	// ProtobufCodec encodes bodies as Protocol Buffers. It is used only for types implementing proto.Message,
+	// other types are encoded by the next codec acceptable to client.
+	ProtobufCodec Codec = protobufCodec{}
+)
+
+// defaultCodecs are used when Router has no codecs configured. First codec is used when client has no preference.
+var defaultCodecs = []Codec{JSONCodec, CBORCodec, ProtobufCodec}
+
+// selectiveCodec is implemented by codecs which can encode only some types.
+type selectiveCodec interface {
+	Codec
+	accepts(v any) bool
+}
+
+// accepts reports whether codec can encode and decode v.
+func accepts(codec Codec, v any) bool {
+	selective, ok := codec.(selectiveCodec)
+	return !ok || selective.accepts(v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return "application/json" }
+
+func (jsonCodec) Decode(r io.Reader, v any) error { return json.NewDecoder(r).Decode(v) }
+
+func (jsonCodec) Encode(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(body)
+	return err
+}
+
+type cborCodec struct{}
+
+func (cborCodec) ContentType() string { return "application/cbor" }
+
+func (cborCodec) Decode(r io.Reader, v any) error {
+	err := cbor.NewDecoder(r).Decode(v)
+
+	var (
+		syntaxErr   *cbor.SyntaxError
+		semanticErr *cbor.SemanticError
+		typeErr     *cbor.UnmarshalTypeError
+	)
+	if errors.As(err, &syntaxErr) || errors.As(err, &semanticErr) || errors.As(err, &typeErr) {
+		return InvalidArgument(fmt.Sprintf("malformed request body: %s", err.Error()))
+	}
+
+	return err
+}
+
+func (cborCodec) Encode(w io.Writer, v any) error { return cbor.NewEncoder(w).Encode(v) }
+
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return "application/protobuf" }
+
+func (protobufCodec) accepts(v any) bool {
+	_, ok := v.(proto.Message)
+	return ok
+}
+
+func (protobufCodec) Decode(r io.Reader, v any) error {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	if err := proto.Unmarshal(body, v.(proto.Message)); err != nil {
+		return InvalidArgument(fmt.Sprintf("malformed request body: %s", err.Error()))
+	}
+
+	return nil
+}
+
+func (protobufCodec) Encode(w io.Writer, v any) error {
+	body, err := proto.Marshal(v.(proto.Message))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(body)
+	return err
+}
+
+// codecsOf returns codecs of the Router which handles the request.
+func codecsOf(r *http.Request) []Codec {
+	if m, ok := r.Context().Value(muxKey).(*mux); ok && len(m.codecs) > 0 {
+		return m.codecs
+	}
+
+	return defaultCodecs
+}
+
+// requestCodec returns codec matching Content-Type of the request which can decode v.
+// Media type parameters are ignored.
+func requestCodec(r *http.Request, v any) (Codec, error) {
+	codecs := make([]Codec, 0)
+	for _, codec := range codecsOf(r) {
+		if accepts(codec, v) {
+			codecs = append(codecs, codec)
+		}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err == nil {
+		for _, codec := range codecs {
+			if strings.EqualFold(mediaType, codec.ContentType()) {
+				return codec, nil
+			}
+		}
+	}
+
+	mediaTypes := make([]string, len(codecs))
+	for i := range codecs {
+		mediaTypes[i] = codecs[i].ContentType()
+	}
+
+	return nil, ClientError{
+		Code:    http.StatusUnsupportedMediaType,
+		Message: strings.Join(mediaTypes, " or ") + " content-type expected",
+	}
+}
+
+// responseCodec negotiates codec which encodes payload by Accept header. Codec with the highest quality value
+// is chosen, exact media types take precedence over wildcards with the same quality.
+// First codec is used if Accept header is missing or none of the codecs is acceptable.
+func responseCodec(r *http.Request, payload any) Codec {
+	codecs := make([]Codec, 0)
+	for _, codec := range codecsOf(r) {
+		if accepts(codec, payload) {
+			codecs = append(codecs, codec)
+		}
+	}
+
+	if len(codecs) == 0 {
+		return JSONCodec
+	}
+
+	ranges := acceptRanges(r)
+
+	best, bestQuality, bestSpecificity := codecs[0], 0.0, 0
+	for _, codec := range codecs {
+		quality, specificity := acceptance(ranges, codec.ContentType())
+		if quality > bestQuality || (quality == bestQuality && specificity > bestSpecificity) {
+			best, bestQuality, bestSpecificity = codec, quality, specificity
+		}
+	}
+
+	if bestQuality == 0 {
+		return codecs[0]
+	}
+
+	return best
+}
+
+// mediaRange is a single media range of Accept header.
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// acceptRanges returns media ranges of Accept headers of the request in the order they are listed.
+func acceptRanges(r *http.Request) []mediaRange {
+	var ranges []mediaRange
+	for _, accept := range r.Header.Values("Accept") {
+		for _, value := range strings.Split(accept, ",") {
+			if rng, ok := parseMediaRange(value); ok {
+				ranges = append(ranges, rng)
+			}
+		}
+	}
+
+	return ranges
+}
+
+// parseMediaRange parses media range with optional quality value, such as "application/cbor;q=0.9".
+func parseMediaRange(value string) (mediaRange, bool) {
+	mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil {
+		return mediaRange{}, false
+	}
+
+	quality := 1.0
+	if q, ok := params["q"]; ok {
+		if quality, err = strconv.ParseFloat(q, 64); err != nil || quality < 0 || quality > 1 {
+			return mediaRange{}, false
+		}
+	}
+
+	return mediaRange{mediaType: mediaType, quality: quality}, true
+}
+
+// acceptance returns quality of the most specific range matching media type and its specificity:
+// 3 for exact match, 2 for "type/*" and 1 for "*/*". Zero values are returned if no range matches.
+func acceptance(ranges []mediaRange, mediaType string) (float64, int) {
+	var (
+		quality     float64
+		specificity int
+	)
+
+	for _, rng := range ranges {
+		var s int
+		switch {
+		case strings.EqualFold(rng.mediaType, mediaType):
+			s = 3
+		case strings.HasSuffix(rng.mediaType, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(rng.mediaType, "*")):
+			s = 2
+		case rng.mediaType == "*/*":
+			s = 1
+		default:
+			continue
+		}
+
+		if s > specificity {
+			quality, specificity = rng.quality, s
+		}
+	}
+
+	return quality, specificity
+}
@@ -0,0 +1,137 @@
+package ferry
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// textCodec encodes testPayload as plain text.
+type textCodec struct{}
+
+func (textCodec) ContentType() string { return "text/plain" }
+
+func (textCodec) Decode(r io.Reader, v any) error {
+	body, err := io.ReadAll(r)
+	v.(*jsonRequest).Value = string(body)
+	return err
+}
+
+func (textCodec) Encode(w io.Writer, v any) error {
+	_, err := io.WriteString(w, v.(*testPayload).Value)
+	return err
+}
+
+func TestResponseCodec(t *testing.T) {
+	tests := []struct {
+		name     string
+		accept   string
+		expected string
+	}{
+		{name: "defaults to JSON", accept: "", expected: "application/json"},
+		{name: "accepts any media type", accept: "*/*", expected: "application/json"},
+		{name: "picks exact media type", accept: "application/cbor", expected: "application/cbor"},
+		{name: "picks highest quality", accept: "application/json;q=0.5, application/cbor", expected: "application/cbor"},
+		{name: "prefers exact media type over wildcard", accept: "*/*, application/cbor", expected: "application/cbor"},
+		{name: "skips refused media type", accept: "application/json;q=0, application/*", expected: "application/cbor"},
+		{name: "falls back to JSON", accept: "application/xml", expected: "application/json"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			r.Header.Set("Accept", tt.accept)
+
+			if codec := responseCodec(r, &testPayload{}); codec.ContentType() != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, codec.ContentType())
+			}
+		})
+	}
+}
+
+func TestCodecs(t *testing.T) {
+	router := NewRouter()
+	svc := testService{}
+	router.Register(Procedure(svc.TestProcedureWithParams))
+
+	t.Run("accepts media type parameters", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", strings.NewReader(`{"value":"test"}`))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusOK || rr.Body.String() != `{"value":"test"}` {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("decodes and encodes CBOR", func(t *testing.T) {
+		body, err := cbor.Marshal(jsonRequest{Value: "test"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/cbor")
+		r.Header.Set("Accept", "application/cbor")
+		router.ServeHTTP(rr, r)
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "application/cbor" {
+			t.Errorf("unexpected content type, got %q", contentType)
+		}
+
+		var response testPayload
+		if err := cbor.Unmarshal(rr.Body.Bytes(), &response); err != nil || response.Value != "test" {
+			t.Errorf("unexpected response, got %+v, %v", response, err)
+		}
+	})
+
+	t.Run("rejects malformed CBOR", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", strings.NewReader("\xff"))
+		r.Header.Set("Content-Type", "application/cbor")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("rejects unsupported media type", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", strings.NewReader(`value`))
+		r.Header.Set("Content-Type", "text/plain")
+		router.ServeHTTP(rr, r)
+
+		expected := `{"error":"application/json or application/cbor content-type expected","code":"unsupported_media_type"}`
+		if rr.Code != http.StatusUnsupportedMediaType || rr.Body.String() != expected {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("registers custom codec", func(t *testing.T) {
+		router := NewRouter(WithCodecs(textCodec{}))
+		router.Register(Procedure(svc.TestProcedureWithParams))
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", strings.NewReader(`test`))
+		r.Header.Set("Content-Type", "text/plain")
+		r.Header.Set("Accept", "text/plain")
+		router.ServeHTTP(rr, r)
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "text/plain; charset=utf-8" {
+			t.Errorf("unexpected content type, got %q", contentType)
+		}
+
+		if rr.Body.String() != "test" {
+			t.Errorf("unexpected response, got %s", rr.Body.String())
+		}
+	})
+}
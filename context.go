@@ -12,8 +12,21 @@ const (
 	ResponseWriter contextKey = iota
 	// Request references *http.Request
 	Request
+	// muxKey references Router which handles the request
+	muxKey
 )
 
 func createContext(w http.ResponseWriter, r *http.Request) context.Context {
 	return context.WithValue(context.WithValue(r.Context(), Request, r), ResponseWriter, w)
 }
+
+// LastEventID returns ID of the last event received by reconnecting Stream client.
+// It is empty if client connects for the first time.
+func LastEventID(ctx context.Context) string {
+	r, ok := ctx.Value(Request).(*http.Request)
+	if !ok {
+		return ""
+	}
+
+	return r.Header.Get("Last-Event-ID")
+}
@@ -1,94 +1,337 @@
 package ferry
 
 import (
+	"encoding"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
+	"net/url"
 	"reflect"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 )
 
-// decodeJSON decodes *http.Request into target struct.
-// Request must have "Content-Type" header set to "application/json".
-func decodeJSON[T any](r *http.Request, v *T) error {
-	if r.Header.Get("Content-Type") != "application/json" {
-		return ClientError{
-			Code:    http.StatusUnsupportedMediaType,
-			Message: "application/json content-type expected",
-		}
+// decodeBody decodes *http.Request body into target struct and validates it.
+// Body is decoded by the Codec matching "Content-Type" header of the request.
+// Forms are decoded by decodeForm if target struct has properties with `form` tag.
+func decodeBody(r *http.Request, v interface{}) error {
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && isForm(mediaType) && hasFormFields(reflect.TypeOf(v).Elem()) {
+		return decodeForm(r, v)
 	}
 
-	if err := json.NewDecoder(io.LimitReader(r.Body, 1024*1024)).Decode(v); err != nil {
-		if err == io.EOF {
-			return ClientError{
-				Code:    http.StatusBadRequest,
-				Message: "empty request body",
-			}
+	codec, err := requestCodec(r, v)
+	if err != nil {
+		return err
+	}
+
+	if err := codec.Decode(&limitedReader{r: r.Body, n: maxBodySize}, v); err != nil {
+		return jsonError(err)
+	}
+
+	if err := r.Body.Close(); err != nil {
+		return err
+	}
+
+	return validate(v, "json")
+}
+
+// hasBody reports whether requests of HTTP method carry request in body. Requests of other methods are bound from query.
+func hasBody(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// maxBodySize is the maximum size of request body in bytes.
+const maxBodySize = 1024 * 1024
+
+// errBodyTooLarge is returned by limitedReader when body exceeds the limit.
+var errBodyTooLarge = errors.New("request body too large")
+
+// limitedReader reads at most n bytes from r. Unlike io.LimitReader, it fails with errBodyTooLarge
+// instead of silently truncating the body.
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n < 0 {
+		return 0, errBodyTooLarge
+	}
+
+	// read one byte past the limit to find out whether there is more
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if l.n < 0 {
+		return n - 1, errBodyTooLarge
+	}
+
+	return n, err
+}
+
+// jsonError maps JSON decoding error to error which can be returned to client.
+// Errors of other codecs reading the body, such as io.EOF, are mapped the same way.
+func jsonError(err error) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return InvalidArgument("empty request body")
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return InvalidArgument("malformed request body: unexpected end of JSON input")
+	case errors.Is(err, errBodyTooLarge):
+		return Error{
+			Status:  http.StatusRequestEntityTooLarge,
+			Code:    statusCode(http.StatusRequestEntityTooLarge),
+			Message: errBodyTooLarge.Error(),
 		}
+	case errors.As(err, &syntaxErr):
+		return InvalidArgument(
+			fmt.Sprintf("malformed request body at offset %d: %s", syntaxErr.Offset, syntaxErr.Error()),
+			jsonErrorDetail{Offset: syntaxErr.Offset},
+		)
+	case errors.As(err, &typeErr):
+		return InvalidArgument(
+			fmt.Sprintf("invalid value for field %q at offset %d: expected %s", typeErr.Field, typeErr.Offset, typeErr.Type),
+			jsonErrorDetail{Field: typeErr.Field, Offset: typeErr.Offset},
+		)
+	default:
 		return fmt.Errorf("decode request body: %w", err)
 	}
+}
 
-	return r.Body.Close()
+// jsonErrorDetail points client to the invalid part of request body.
+type jsonErrorDetail struct {
+	Field  string `json:"field,omitempty"`
+	Offset int64  `json:"offset"`
 }
 
-// decodeQuery decodes query values from http.Request into target struct.
+// decodeQuery decodes query values from http.Request into target struct and validates it.
 // This function maps r.URL.Query values to struct properties by `query` tag.
-func decodeQuery[T any](r *http.Request, v *T) error {
-	targetType := reflect.TypeOf(v)
-	targetValue := reflect.ValueOf(v)
-
-	for i := 0; i < targetType.Elem().NumField(); i++ {
-		field := targetType.Elem().Field(i)
-		key := field.Tag.Get("query")
-		kind := field.Type.Kind()
-		// Get the value from query params with given key
-		val := r.URL.Query().Get(key)
-		//  Get reference of field value provided to input `d`
-		result := targetValue.Elem().Field(i)
-
-		switch kind {
-		case reflect.String:
-			result.SetString(val)
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			num, err := strconv.Atoi(val)
-			if err != nil {
-				return ClientError{
-					Code:    http.StatusBadRequest,
-					Message: fmt.Sprintf("can not convert %q to int", val),
-				}
-			}
-			result.SetInt(int64(num))
-		case reflect.Float32:
-			num, err := strconv.ParseFloat(val, 32)
-			if err != nil {
-				return ClientError{
-					Code:    http.StatusBadRequest,
-					Message: fmt.Sprintf("can not convert %q to float32", val),
-				}
+func decodeQuery(r *http.Request, v interface{}) error {
+	if err := bindValues(r.URL.Query(), v, "query"); err != nil {
+		return err
+	}
+
+	return validate(v, "query")
+}
+
+// bindValues maps values to properties of target struct by given tag. Files are bound separately by bindFiles.
+// Repeated values are bound to slices, properties of nested structs are bound by "parent.child" or "parent[child]" keys.
+// Missing and empty values leave properties untouched, unless default value is set with `default` tag.
+func bindValues(values url.Values, v interface{}, tag string) error {
+	values = normalizeKeys(values)
+	target := reflect.ValueOf(v).Elem()
+
+	for _, field := range bindingFields(target.Type(), tag) {
+		raw := nonEmpty(values[field.key])
+		if len(raw) == 0 {
+			var ok bool
+			if raw, ok = defaultValues(field); !ok {
+				continue
 			}
-			result.SetFloat(num)
-		case reflect.Float64:
-			num, err := strconv.ParseFloat(val, 64)
-			if err != nil {
-				return ClientError{
-					Code:    http.StatusBadRequest,
-					Message: fmt.Sprintf("can not convert %q to float64", val),
-				}
+		}
+
+		if err := setValues(fieldByIndex(target, field.index), field.key, raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// defaultValues returns values of `default` tag of the property. Defaults of slices are separated by commas.
+func defaultValues(field bindingField) ([]string, bool) {
+	def, ok := field.Tag.Lookup("default")
+	if !ok {
+		return nil, false
+	}
+
+	if indirectType(field.Type).Kind() == reflect.Slice {
+		return strings.Split(def, ","), true
+	}
+
+	return []string{def}, true
+}
+
+// checkDefaults converts values of `default` tags of properties bound by given tag,
+// so defaults which do not fit the property type are reported on registration.
+func checkDefaults(t reflect.Type, tag string) error {
+	for _, field := range bindingFields(t, tag) {
+		values, ok := defaultValues(field)
+		if !ok {
+			continue
+		}
+
+		if err := setValues(reflect.New(field.Type).Elem(), field.key, values); err != nil {
+			return fmt.Errorf("invalid default value of %s: %w", field.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// normalizeKeys rewrites "parent[child]" keys to "parent.child" and "list[]" keys to "list".
+// Values of keys which are rewritten to the same key are merged in order of sorted original keys.
+func normalizeKeys(values url.Values) url.Values {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	normalized := make(url.Values, len(values))
+	for _, key := range keys {
+		list := values[key]
+		key = strings.ReplaceAll(key, "[]", "")
+		key = strings.ReplaceAll(strings.ReplaceAll(key, "]", ""), "[", ".")
+		normalized[key] = append(normalized[key], list...)
+	}
+
+	return normalized
+}
+
+// nonEmpty returns values which are not empty strings.
+func nonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+
+	return result
+}
+
+// fieldByIndex returns nested property of struct allocating nil pointers to structs along the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
 			}
-			result.SetFloat(num)
-		case reflect.Bool:
-			boolean, err := strconv.ParseBool(val)
-			if err != nil {
-				return ClientError{
-					Code:    http.StatusBadRequest,
-					Message: fmt.Sprintf("can not convert %q to bool", val),
-				}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+
+	return v
+}
+
+// setValues converts strings to the type of result and sets it. All values are set to slices,
+// other types are set from the first value. Pointers are allocated.
+func setValues(result reflect.Value, key string, values []string) error {
+	if result.Kind() == reflect.Ptr {
+		value := reflect.New(result.Type().Elem())
+		if err := setValues(value.Elem(), key, values); err != nil {
+			return err
+		}
+		result.Set(value)
+		return nil
+	}
+
+	if result.Kind() != reflect.Slice || isText(result.Type()) {
+		return setValue(result, key, values[0])
+	}
+
+	slice := reflect.MakeSlice(result.Type(), len(values), len(values))
+	for i, value := range values {
+		if err := setValues(slice.Index(i), key, []string{value}); err != nil {
+			return err
+		}
+	}
+	result.Set(slice)
+
+	return nil
+}
+
+// isText reports whether values of type t are set by encoding.TextUnmarshaler.
+func isText(t reflect.Type) bool {
+	return t != durationType && reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// setValue converts string to the type of result and sets it.
+func setValue(result reflect.Value, key, val string) error {
+	if isText(result.Type()) {
+		if err := result.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(val)); err != nil {
+			return valueError(key, val, result.Type(), err)
+		}
+		return nil
+	}
+
+	if result.Type() == durationType {
+		duration, err := time.ParseDuration(val)
+		if err != nil {
+			// durations encoded as JSON numbers are sent in nanoseconds
+			nanoseconds, intErr := strconv.ParseInt(val, 10, 64)
+			if intErr != nil {
+				return valueError(key, val, result.Type(), err)
 			}
-			result.SetBool(boolean)
-		default:
-			return fmt.Errorf("unsupported kind %q in request query", kind)
+			duration = time.Duration(nanoseconds)
 		}
+		result.SetInt(int64(duration))
+		return nil
 	}
+
+	switch kind := result.Kind(); kind {
+	case reflect.String:
+		result.SetString(val)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, err := strconv.ParseInt(val, 10, result.Type().Bits())
+		if err != nil {
+			return valueError(key, val, result.Type(), err)
+		}
+		result.SetInt(num)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		num, err := strconv.ParseUint(val, 10, result.Type().Bits())
+		if err != nil {
+			return valueError(key, val, result.Type(), err)
+		}
+		result.SetUint(num)
+	case reflect.Float32, reflect.Float64:
+		num, err := strconv.ParseFloat(val, result.Type().Bits())
+		if err != nil {
+			return valueError(key, val, result.Type(), err)
+		}
+		result.SetFloat(num)
+	case reflect.Bool:
+		boolean, err := strconv.ParseBool(val)
+		if err != nil {
+			return valueError(key, val, result.Type(), err)
+		}
+		result.SetBool(boolean)
+	default:
+		return fmt.Errorf("unsupported kind %q in request", kind)
+	}
+
 	return nil
 }
+
+// valueError reports value which can not be converted to type t.
+func valueError(key, val string, t reflect.Type, err error) error {
+	message := fmt.Sprintf("can not convert %q of %q to %s", val, key, t)
+	if errors.Is(err, strconv.ErrRange) {
+		message = fmt.Sprintf("value %q of %q is out of %s range", val, key, t)
+	}
+
+	return ClientError{
+		Code:    http.StatusBadRequest,
+		Message: message,
+	}
+}
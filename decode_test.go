@@ -0,0 +1,191 @@
+package ferry
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		message string
+	}{
+		{
+			name:    "rejects empty body",
+			body:    "",
+			status:  http.StatusBadRequest,
+			message: "empty request body",
+		},
+		{
+			name:    "rejects malformed body",
+			body:    `{"value": x}`,
+			status:  http.StatusBadRequest,
+			message: "malformed request body at offset 11: invalid character 'x' looking for beginning of value",
+		},
+		{
+			name:    "rejects truncated body",
+			body:    `{"value": "`,
+			status:  http.StatusBadRequest,
+			message: "malformed request body: unexpected end of JSON input",
+		},
+		{
+			name:    "rejects invalid type",
+			body:    `{"value": 42}`,
+			status:  http.StatusBadRequest,
+			message: `invalid value for field "value" at offset 12: expected string`,
+		},
+		{
+			name:    "rejects too large body",
+			body:    `{"value": "` + strings.Repeat("a", maxBodySize) + `"}`,
+			status:  http.StatusRequestEntityTooLarge,
+			message: "request body too large",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", "application/json")
+
+			err := decodeBody(r, &jsonRequest{})
+
+			var coder StatusCoder
+			if !errors.As(err, &coder) {
+				t.Fatalf("expected StatusCoder, got %v", err)
+			}
+
+			if coder.StatusCode() != tt.status || coder.Error() != tt.message {
+				t.Errorf("unexpected error, got %d %q", coder.StatusCode(), coder.Error())
+			}
+		})
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	t.Run("reads body of exact size", func(t *testing.T) {
+		body, err := io.ReadAll(&limitedReader{r: strings.NewReader("12345"), n: 5})
+		if err != nil || string(body) != "12345" {
+			t.Errorf("unexpected result, got %q, %v", body, err)
+		}
+	})
+
+	t.Run("fails on larger body", func(t *testing.T) {
+		body, err := io.ReadAll(&limitedReader{r: strings.NewReader("123456"), n: 5})
+		if !errors.Is(err, errBodyTooLarge) || string(body) != "12345" {
+			t.Errorf("unexpected result, got %q, %v", body, err)
+		}
+	})
+}
+
+type queryFilter struct {
+	Name string   `query:"name"`
+	Tags []string `query:"tag"`
+}
+
+type Paging struct {
+	Page int `query:"page" default:"1"`
+}
+
+type bindingRequest struct {
+	Paging
+	Limit    uint8         `query:"limit" default:"10"`
+	Offset   *int          `query:"offset"`
+	IDs      []int64       `query:"id"`
+	Since    time.Time     `query:"since"`
+	Timeout  time.Duration `query:"timeout"`
+	Address  net.IP        `query:"address"`
+	Filter   queryFilter   `query:"filter"`
+	Sort     *queryFilter  `query:"sort"`
+	Optional *bool         `query:"optional"`
+}
+
+func TestDecodeQuery(t *testing.T) {
+	t.Run("binds values", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/?offset=0&id=1&id=2&since=2022-01-02T15:04:05Z&timeout=1m30s"+
+			"&address=127.0.0.1&filter.name=john&filter[tag]=a&filter[tag][]=b&optional=", nil)
+
+		var request bindingRequest
+		if err := decodeQuery(r, &request); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		offset := 0
+		expected := bindingRequest{
+			Paging:  Paging{Page: 1},
+			Limit:   10,
+			Offset:  &offset,
+			IDs:     []int64{1, 2},
+			Since:   time.Date(2022, 1, 2, 15, 4, 5, 0, time.UTC),
+			Timeout: 90 * time.Second,
+			Address: net.ParseIP("127.0.0.1"),
+			Filter:  queryFilter{Name: "john", Tags: []string{"a", "b"}},
+		}
+		if !reflect.DeepEqual(request, expected) {
+			t.Errorf("unexpected request, got %+v", request)
+		}
+	})
+
+	t.Run("allocates nested pointers", func(t *testing.T) {
+		var request bindingRequest
+		if err := decodeQuery(httptest.NewRequest(http.MethodGet, "/?sort.name=date&timeout=1000", nil), &request); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if request.Sort == nil || request.Sort.Name != "date" || request.Timeout != time.Microsecond {
+			t.Errorf("unexpected request, got %+v", request)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{
+			name:    "rejects overflow",
+			query:   "limit=256",
+			message: `value "256" of "limit" is out of uint8 range`,
+		},
+		{
+			name:    "rejects negative unsigned",
+			query:   "limit=-1",
+			message: `can not convert "-1" of "limit" to uint8`,
+		},
+		{
+			name:    "rejects invalid slice element",
+			query:   "id=1&id=x",
+			message: `can not convert "x" of "id" to int64`,
+		},
+		{
+			name:    "rejects invalid text",
+			query:   "since=yesterday",
+			message: `can not convert "yesterday" of "since" to time.Time`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeQuery(httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil), &bindingRequest{})
+
+			var coder StatusCoder
+			if !errors.As(err, &coder) {
+				t.Fatalf("expected StatusCoder, got %v", err)
+			}
+
+			if coder.StatusCode() != http.StatusBadRequest || coder.Error() != tt.message {
+				t.Errorf("unexpected error, got %d %q", coder.StatusCode(), coder.Error())
+			}
+		})
+	}
+}
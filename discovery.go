@@ -19,16 +19,42 @@ func ServiceDiscovery(router chi.Router) http.HandlerFunc {
 			m = h.meta
 		case *streamHandler:
 			m = h.meta
+		case *bidiHandler:
+			m = h.meta
+		case *clientStreamHandler:
+			m = h.meta
 		default:
 			return nil
 		}
 
-		endpoints = append(endpoints, endpoint{
-			Method: method,
-			Path:   route,
-			Body:   m.body,
-			Query:  m.query,
-		})
+		e := endpoint{
+			Method:   method,
+			Path:     route,
+			Body:     m.body,
+			Form:     m.form,
+			Query:    m.query,
+			Response: m.payload,
+		}
+		switch handler.(type) {
+		case *procedureHandler:
+			if !hasBody(method) {
+				// procedure binds request from query
+				e.Body, e.Form = nil, nil
+			}
+		case *streamHandler:
+			e.Events = eventNames(m.response)
+			if hasBody(method) {
+				// stream decodes request from body instead of query
+				e.Body, e.Query = rootSchema(m.request), nil
+			}
+		case *bidiHandler:
+			e.Transport = "websocket"
+			e.Events = eventNames(m.response)
+		case *clientStreamHandler:
+			e.Transport = "ndjson"
+		}
+
+		endpoints = append(endpoints, e)
 
 		return nil
 	})
@@ -50,10 +76,14 @@ func prependHost(url string, input []endpoint) []endpoint {
 
 	for i := range input {
 		result[i] = endpoint{
-			Method: input[i].Method,
-			Path:   url + input[i].Path,
-			Body:   input[i].Body,
-			Query:  input[i].Query,
+			Method:    input[i].Method,
+			Path:      url + input[i].Path,
+			Body:      input[i].Body,
+			Form:      input[i].Form,
+			Query:     input[i].Query,
+			Response:  input[i].Response,
+			Events:    input[i].Events,
+			Transport: input[i].Transport,
 		}
 	}
 
@@ -61,8 +91,13 @@ func prependHost(url string, input []endpoint) []endpoint {
 }
 
 type endpoint struct {
-	Method string            `json:"method"`
-	Path   string            `json:"path"`
-	Body   map[string]string `json:"body,omitempty"`
-	Query  map[string]string `json:"query,omitempty"`
+	Method   string   `json:"method"`
+	Path     string   `json:"path"`
+	Body     *Schema  `json:"body,omitempty"`
+	Form     *Schema  `json:"form,omitempty"`
+	Query    *Schema  `json:"query,omitempty"`
+	Response *Schema  `json:"response,omitempty"`
+	Events   []string `json:"events,omitempty"`
+	// Transport is set to "websocket" for BidiStream and "ndjson" for ClientStream endpoints.
+	Transport string `json:"transport,omitempty"`
 }
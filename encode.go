@@ -1,18 +1,30 @@
 package ferry
 
 import (
+	"bytes"
 	"compress/gzip"
-	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 )
 
-// Encode encodes payload to JSON and writes it to http.ResponseWriter along with all required headers.
+// Encode encodes payload and writes it to http.ResponseWriter along with all required headers.
+// Payload is encoded by the Codec negotiated by "Accept" header of the request, JSON is used by default.
+// Binary payloads are written as they are.
 func Encode(w http.ResponseWriter, r *http.Request, status int, payload any) error {
-	body, err := json.Marshal(payload)
-	if err != nil {
+	if binary, ok := payload.(Binary); ok {
+		return writeBinary(w, r, status, binary)
+	}
+
+	codec := responseCodec(r, payload)
+	return encode(w, r, status, codec.ContentType(), codec, payload)
+}
+
+// encode encodes payload with given codec and writes it with given media type.
+func encode(w http.ResponseWriter, r *http.Request, status int, mediaType string, codec Codec, payload any) error {
+	var body bytes.Buffer
+	if err := codec.Encode(&body, payload); err != nil {
 		return fmt.Errorf("marshal payload: %w", err)
 	}
 
@@ -24,10 +36,15 @@ func Encode(w http.ResponseWriter, r *http.Request, status int, payload any) err
 		defer gzw.Close()
 	}
 
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	// textual media types are always encoded as UTF-8
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") || strings.HasPrefix(mediaType, "text/") {
+		mediaType += "; charset=utf-8"
+	}
+
+	w.Header().Set("Content-Type", mediaType)
 	w.WriteHeader(status)
 
-	if _, err := out.Write(body); err != nil {
+	if _, err := out.Write(body.Bytes()); err != nil {
 		return fmt.Errorf("write body: %w", err)
 	}
 
@@ -1,6 +1,14 @@
 package ferry
 
-import "net/http"
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // ErrorHandler handles errors in Handler methods. Can be assigned to router with WithErrorHandler option.
 type ErrorHandler func(w http.ResponseWriter, r *http.Request, err error)
@@ -13,17 +21,305 @@ type ClientError struct {
 
 func (e ClientError) Error() string { return e.Message }
 
-// DefaultErrorHandler knows how to encode ClientError.
+// StatusCode implements StatusCoder.
+func (e ClientError) StatusCode() int { return e.Code }
+
+// StatusCoder can be implemented by any error to choose HTTP status returned to client.
+// Message returned by Error is considered public and is sent to client as well.
+type StatusCoder interface {
+	error
+	StatusCode() int
+}
+
+// Error is an error with HTTP status and machine-readable code which can be returned to client.
+// Details can carry any JSON encodable values describing the error.
+// If RetryAfter is set, it is sent to client in Retry-After header. Status is derived from Code when it is not set.
+type Error struct {
+	Status     int           `json:"-"`
+	Code       string        `json:"code"`
+	Message    string        `json:"error"`
+	Details    []interface{} `json:"details,omitempty"`
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e Error) Error() string { return e.Message }
+
+// StatusCode implements StatusCoder.
+func (e Error) StatusCode() int {
+	if e.Status == 0 {
+		return codeStatus(e.Code)
+	}
+
+	return e.Status
+}
+
+// As allows Error to be matched as ClientError with errors.As.
+func (e Error) As(target interface{}) bool {
+	if clientErr, ok := target.(*ClientError); ok {
+		*clientErr = ClientError{Code: e.Status, Message: e.Message}
+		return true
+	}
+
+	return false
+}
+
+// StatusCode implements StatusCoder.
+func (e ValidationError) StatusCode() int { return http.StatusUnprocessableEntity }
+
+// As allows ValidationError to be matched as ClientError with errors.As.
+func (e ValidationError) As(target interface{}) bool {
+	if clientErr, ok := target.(*ClientError); ok {
+		*clientErr = ClientError{Code: http.StatusUnprocessableEntity, Message: e.Message}
+		return true
+	}
+
+	return false
+}
+
+// Error codes used by Error constructors.
+const (
+	CodeInvalidArgument   = "invalid_argument"
+	CodeUnauthenticated   = "unauthenticated"
+	CodePermissionDenied  = "permission_denied"
+	CodeNotFound          = "not_found"
+	CodeAlreadyExists     = "already_exists"
+	CodeResourceExhausted = "resource_exhausted"
+	CodeInternal          = "internal"
+	CodeCanceled          = "canceled"
+	CodeDeadlineExceeded  = "deadline_exceeded"
+	CodeUnimplemented     = "unimplemented"
+	CodeUnavailable       = "unavailable"
+)
+
+// InvalidArgument creates 400 Bad Request error.
+func InvalidArgument(message string, details ...interface{}) Error {
+	return Error{Status: http.StatusBadRequest, Code: CodeInvalidArgument, Message: message, Details: details}
+}
+
+// Unauthenticated creates 401 Unauthorized error.
+func Unauthenticated(message string, details ...interface{}) Error {
+	return Error{Status: http.StatusUnauthorized, Code: CodeUnauthenticated, Message: message, Details: details}
+}
+
+// PermissionDenied creates 403 Forbidden error.
+func PermissionDenied(message string, details ...interface{}) Error {
+	return Error{Status: http.StatusForbidden, Code: CodePermissionDenied, Message: message, Details: details}
+}
+
+// NotFound creates 404 Not Found error.
+func NotFound(message string, details ...interface{}) Error {
+	return Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: message, Details: details}
+}
+
+// AlreadyExists creates 409 Conflict error.
+func AlreadyExists(message string, details ...interface{}) Error {
+	return Error{Status: http.StatusConflict, Code: CodeAlreadyExists, Message: message, Details: details}
+}
+
+// ResourceExhausted creates 429 Too Many Requests error. Client is asked to retry after given duration.
+func ResourceExhausted(message string, retryAfter time.Duration, details ...interface{}) Error {
+	return Error{Status: http.StatusTooManyRequests, Code: CodeResourceExhausted, Message: message, Details: details, RetryAfter: retryAfter}
+}
+
+// Internal creates 500 Internal Server Error error. Message is returned to client.
+func Internal(message string, details ...interface{}) Error {
+	return Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: message, Details: details}
+}
+
+// Unimplemented creates 501 Not Implemented error.
+func Unimplemented(message string, details ...interface{}) Error {
+	return Error{Status: http.StatusNotImplemented, Code: CodeUnimplemented, Message: message, Details: details}
+}
+
+// Unavailable creates 503 Service Unavailable error. Client is asked to retry after given duration.
+func Unavailable(message string, retryAfter time.Duration, details ...interface{}) Error {
+	return Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable, Message: message, Details: details, RetryAfter: retryAfter}
+}
+
+// DefaultErrorHandler knows how to encode Error, ValidationError and any StatusCoder, including wrapped ones.
+// All of them are encoded as JSON object with "error" message and "code".
+// Canceled requests are reported with 499 status and exceeded deadlines with 504 Gateway Timeout.
 // In case of unexpected error it returns 500 Internal Server Error.
 var DefaultErrorHandler ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
-	switch tErr := err.(type) {
-	case ClientError:
-		Encode(w, r, tErr.Code, tErr)
+	body := newErrorBody(err)
+	setRetryAfter(w, body.retryAfter)
+	Encode(w, r, body.status, body)
+}
+
+// ProblemErrorHandler encodes errors the same way DefaultErrorHandler does,
+// but uses RFC 7807 "application/problem+json" format.
+var ProblemErrorHandler ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+	body := newErrorBody(err)
+	setRetryAfter(w, body.retryAfter)
+	encode(w, r, body.status, "application/problem+json", JSONCodec, problem{
+		Type:    "about:blank",
+		Title:   http.StatusText(body.status),
+		Status:  body.status,
+		Detail:  body.Message,
+		Code:    body.Code,
+		Details: body.Details,
+		Fields:  body.Fields,
+	})
+}
+
+// errorBody is the representation of error which is returned to client.
+type errorBody struct {
+	Message string        `json:"error"`
+	Code    string        `json:"code"`
+	Details []interface{} `json:"details,omitempty"`
+	Fields  []FieldError  `json:"fields,omitempty"`
+
+	status     int
+	retryAfter time.Duration
+}
+
+// newErrorBody maps error to its client representation. Unexpected errors are hidden behind generic message.
+func newErrorBody(err error) errorBody {
+	var (
+		validationErr ValidationError
+		ferryErr      Error
+		coder         StatusCoder
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		return errorBody{
+			Message: validationErr.Message,
+			Code:    CodeInvalidArgument,
+			Fields:  validationErr.Fields,
+			status:  http.StatusUnprocessableEntity,
+		}
+	case errors.As(err, &ferryErr):
+		status, code := ferryErr.Status, ferryErr.Code
+		if status == 0 {
+			status = codeStatus(code)
+		}
+		if code == "" {
+			code = statusCode(status)
+		}
+		return errorBody{
+			Message:    ferryErr.Message,
+			Code:       code,
+			Details:    ferryErr.Details,
+			status:     status,
+			retryAfter: ferryErr.RetryAfter,
+		}
+	case errors.As(err, &coder):
+		return errorBody{
+			Message: coder.Error(),
+			Code:    statusCode(coder.StatusCode()),
+			status:  coder.StatusCode(),
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return errorBody{
+			Message: "deadline exceeded",
+			Code:    CodeDeadlineExceeded,
+			status:  http.StatusGatewayTimeout,
+		}
+	case errors.Is(err, context.Canceled):
+		return errorBody{
+			Message: "request canceled",
+			Code:    CodeCanceled,
+			status:  statusClientClosedRequest,
+		}
 	default:
-		Encode(w, r, http.StatusInternalServerError, serverError{"internal server error"})
+		return errorBody{
+			Message: "internal server error",
+			Code:    CodeInternal,
+			status:  http.StatusInternalServerError,
+		}
 	}
 }
 
-type serverError struct {
-	Message string `json:"error"`
+// err converts decoded error body back to ValidationError or Error.
+func (b errorBody) err() error {
+	if len(b.Fields) > 0 {
+		return ValidationError{Message: b.Message, Fields: b.Fields}
+	}
+
+	return Error{
+		Status:     b.status,
+		Code:       b.Code,
+		Message:    b.Message,
+		Details:    b.Details,
+		RetryAfter: b.retryAfter,
+	}
+}
+
+// statusClientClosedRequest is non-standard status used when client goes away before response is written.
+const statusClientClosedRequest = 499
+
+// statusCode returns error code for HTTP status.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return CodeInvalidArgument
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodePermissionDenied
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeAlreadyExists
+	case http.StatusTooManyRequests:
+		return CodeResourceExhausted
+	case http.StatusInternalServerError:
+		return CodeInternal
+	case http.StatusNotImplemented:
+		return CodeUnimplemented
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeDeadlineExceeded
+	case statusClientClosedRequest:
+		return CodeCanceled
+	default:
+		return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	}
+}
+
+// codeStatus returns HTTP status for error code. It is used when status is not known, e.g. for stream errors.
+func codeStatus(code string) int {
+	switch code {
+	case CodeInvalidArgument:
+		return http.StatusBadRequest
+	case CodeUnauthenticated:
+		return http.StatusUnauthorized
+	case CodePermissionDenied:
+		return http.StatusForbidden
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeAlreadyExists:
+		return http.StatusConflict
+	case CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case CodeUnimplemented:
+		return http.StatusNotImplemented
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case CodeCanceled:
+		return statusClientClosedRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func setRetryAfter(w http.ResponseWriter, retryAfter time.Duration) {
+	if retryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+}
+
+// problem is RFC 7807 problem details object extended with error code, details and invalid fields.
+type problem struct {
+	Type    string        `json:"type"`
+	Title   string        `json:"title"`
+	Status  int           `json:"status"`
+	Detail  string        `json:"detail"`
+	Code    string        `json:"code"`
+	Details []interface{} `json:"details,omitempty"`
+	Fields  []FieldError  `json:"fields,omitempty"`
 }
@@ -0,0 +1,146 @@
+package ferry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type quotaError struct{}
+
+func (quotaError) Error() string { return "quota exceeded" }
+
+func (quotaError) StatusCode() int { return http.StatusPaymentRequired }
+
+func TestDefaultErrorHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		body       string
+		retryAfter string
+	}{
+		{
+			name:   "encodes error",
+			err:    NotFound("user not found", map[string]string{"id": "42"}),
+			status: http.StatusNotFound,
+			body:   `{"error":"user not found","code":"not_found","details":[{"id":"42"}]}`,
+		},
+		{
+			name:   "encodes wrapped error",
+			err:    fmt.Errorf("get user: %w", PermissionDenied("forbidden")),
+			status: http.StatusForbidden,
+			body:   `{"error":"forbidden","code":"permission_denied"}`,
+		},
+		{
+			name:   "encodes wrapped client error",
+			err:    fmt.Errorf("get user: %w", ClientError{Code: http.StatusConflict, Message: "conflict"}),
+			status: http.StatusConflict,
+			body:   `{"error":"conflict","code":"already_exists"}`,
+		},
+		{
+			name:   "derives code from status",
+			err:    Error{Status: http.StatusTeapot, Message: "teapot"},
+			status: http.StatusTeapot,
+			body:   `{"error":"teapot","code":"i'm_a_teapot"}`,
+		},
+		{
+			name:   "derives status from code",
+			err:    Error{Code: CodeNotFound, Message: "missing"},
+			status: http.StatusNotFound,
+			body:   `{"error":"missing","code":"not_found"}`,
+		},
+		{
+			name:   "defaults to internal error",
+			err:    Error{Message: "broken"},
+			status: http.StatusInternalServerError,
+			body:   `{"error":"broken","code":"internal"}`,
+		},
+		{
+			name:   "encodes validation error",
+			err:    ValidationError{Message: "validation failed", Fields: []FieldError{{Field: "name", Rule: "required", Message: "is required"}}},
+			status: http.StatusUnprocessableEntity,
+			body:   `{"error":"validation failed","code":"invalid_argument","fields":[{"field":"name","rule":"required","message":"is required"}]}`,
+		},
+		{
+			name:       "sets retry after header",
+			err:        ResourceExhausted("slow down", 1500*time.Millisecond),
+			status:     http.StatusTooManyRequests,
+			body:       `{"error":"slow down","code":"resource_exhausted"}`,
+			retryAfter: "2",
+		},
+		{
+			name:   "encodes wrapped status coder",
+			err:    fmt.Errorf("charge: %w", quotaError{}),
+			status: http.StatusPaymentRequired,
+			body:   `{"error":"quota exceeded","code":"payment_required"}`,
+		},
+		{
+			name:   "encodes deadline exceeded",
+			err:    fmt.Errorf("query: %w", context.DeadlineExceeded),
+			status: http.StatusGatewayTimeout,
+			body:   `{"error":"deadline exceeded","code":"deadline_exceeded"}`,
+		},
+		{
+			name:   "encodes canceled request",
+			err:    context.Canceled,
+			status: statusClientClosedRequest,
+			body:   `{"error":"request canceled","code":"canceled"}`,
+		},
+		{
+			name:   "hides unexpected error",
+			err:    errors.New("database is down"),
+			status: http.StatusInternalServerError,
+			body:   `{"error":"internal server error","code":"internal"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			DefaultErrorHandler(recorder, httptest.NewRequest(http.MethodPost, "/", nil), tt.err)
+
+			if recorder.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+
+			if body := recorder.Body.String(); body != tt.body {
+				t.Errorf("unexpected body, got %s", body)
+			}
+
+			if header := recorder.Header().Get("Retry-After"); header != tt.retryAfter {
+				t.Errorf("unexpected Retry-After header, got %q", header)
+			}
+		})
+	}
+}
+
+func TestProblemErrorHandler(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	ProblemErrorHandler(recorder, httptest.NewRequest(http.MethodPost, "/", nil), fmt.Errorf("wrapped: %w", NotFound("user not found")))
+
+	if recorder.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, recorder.Code)
+	}
+
+	if contentType := recorder.Header().Get("Content-Type"); contentType != "application/problem+json; charset=utf-8" {
+		t.Errorf("unexpected content type, got %q", contentType)
+	}
+
+	var body problem
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+
+	expected := problem{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "user not found", Code: CodeNotFound}
+	if body.Type != expected.Type || body.Title != expected.Title || body.Status != expected.Status ||
+		body.Detail != expected.Detail || body.Code != expected.Code {
+		t.Errorf("unexpected problem, got %+v", body)
+	}
+}
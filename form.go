@@ -0,0 +1,339 @@
+package ferry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+)
+
+// File is a file uploaded with multipart/form-data request. Request properties of File or []File type
+// are bound to file parts by `form` tag. File content is read from File itself.
+// Files are closed and removed from disk after handler returns.
+type File struct {
+	// Name is the file name sent by client.
+	Name        string
+	Size        int64
+	ContentType string
+
+	io.ReadCloser
+}
+
+var (
+	fileType      = reflect.TypeOf(File{})
+	fileSliceType = reflect.TypeOf([]File{})
+)
+
+// isFile reports whether property of type t is bound to uploaded files.
+func isFile(t reflect.Type) bool {
+	return t == fileType || t == fileSliceType
+}
+
+// FormLimits limits size of application/x-www-form-urlencoded and multipart/form-data request bodies.
+type FormLimits struct {
+	// MaxMemory is the number of bytes of uploaded files kept in memory,
+	// files which do not fit are stored in temporary files on disk.
+	MaxMemory int64
+	// MaxPartSize is the maximum size of a single field or file.
+	MaxPartSize int64
+	// MaxBodySize is the maximum size of the whole request body.
+	MaxBodySize int64
+}
+
+// defaultFormLimits are used when Router has no form limits configured.
+var defaultFormLimits = FormLimits{
+	MaxMemory:   10 << 20,
+	MaxPartSize: 10 << 20,
+	MaxBodySize: 32 << 20,
+}
+
+// formLimitsOf returns form limits of the Router which handles the request.
+func formLimitsOf(r *http.Request) FormLimits {
+	if m, ok := r.Context().Value(muxKey).(*mux); ok {
+		return m.formLimits
+	}
+
+	return defaultFormLimits
+}
+
+// isForm reports whether media type is decoded by decodeForm.
+func isForm(mediaType string) bool {
+	return mediaType == "application/x-www-form-urlencoded" || mediaType == "multipart/form-data"
+}
+
+// decodeForm decodes form or multipart body of http.Request into target struct and validates it.
+// Fields are mapped to struct properties by `form` tag.
+func decodeForm(r *http.Request, v interface{}) error {
+	limits := formLimitsOf(r)
+	body := &limitedReader{r: r.Body, n: limits.MaxBodySize}
+
+	mediaType, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var (
+		values url.Values
+		files  map[string][]File
+		err    error
+	)
+	if mediaType == "multipart/form-data" {
+		values, files, err = readMultipart(multipart.NewReader(body, params["boundary"]), limits)
+	} else {
+		values, err = readURLEncoded(body)
+	}
+	if body.n < 0 {
+		// body can exceed the limit after the last part was read
+		closeFiles(files)
+		err = errBodyTooLarge
+	}
+	if err != nil {
+		return formError(err)
+	}
+
+	if err := r.Body.Close(); err != nil {
+		closeFiles(files)
+		return err
+	}
+
+	bindFiles(files, v)
+	if err := bindValues(values, v, "form"); err != nil {
+		closeRequestFiles(v)
+		return err
+	}
+
+	if err := validate(v, "form"); err != nil {
+		closeRequestFiles(v)
+		return err
+	}
+
+	return nil
+}
+
+// readURLEncoded reads application/x-www-form-urlencoded body.
+func readURLEncoded(body io.Reader) (url.Values, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := url.ParseQuery(string(data))
+	if err != nil {
+		return nil, InvalidArgument(fmt.Sprintf("malformed request body: %s", err.Error()))
+	}
+
+	return values, nil
+}
+
+// readMultipart reads all parts of multipart/form-data body. Files are kept in memory
+// until limits.MaxMemory is reached, the rest are stored in temporary files.
+func readMultipart(reader *multipart.Reader, limits FormLimits) (url.Values, map[string][]File, error) {
+	values := make(url.Values)
+	files := make(map[string][]File)
+	memory := limits.MaxMemory
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			return values, files, nil
+		}
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, err
+		}
+
+		name := part.FormName()
+		if name == "" {
+			continue
+		}
+
+		if part.FileName() == "" {
+			var data bytes.Buffer
+			if _, err := io.Copy(&data, &limitedReader{r: part, n: limits.MaxPartSize}); err != nil {
+				closeFiles(files)
+				return nil, nil, partError(name, err)
+			}
+			values.Add(name, data.String())
+			continue
+		}
+
+		file, err := readFile(part, limits.MaxPartSize, &memory)
+		if err != nil {
+			closeFiles(files)
+			return nil, nil, partError(name, err)
+		}
+		files[name] = append(files[name], file)
+	}
+}
+
+// readFile reads file part. File is kept in memory if its size does not exceed memory,
+// which is reduced by file size, otherwise it is stored in temporary file.
+func readFile(part *multipart.Part, maxSize int64, memory *int64) (File, error) {
+	file := File{Name: part.FileName(), ContentType: part.Header.Get("Content-Type")}
+	content := &limitedReader{r: part, n: maxSize}
+
+	// read one byte more than fits in memory to find out whether file has to be stored on disk
+	var data bytes.Buffer
+	n, err := io.CopyN(&data, content, *memory+1)
+	if err != nil && err != io.EOF {
+		return File{}, err
+	}
+	if n <= *memory {
+		*memory -= n
+		file.Size, file.ReadCloser = n, io.NopCloser(&data)
+		return file, nil
+	}
+
+	tmp, err := os.CreateTemp("", "ferry-upload-*")
+	if err != nil {
+		return File{}, err
+	}
+	stored := &tempFile{File: tmp}
+
+	size, err := io.Copy(tmp, io.MultiReader(&data, content))
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		stored.Close()
+		return File{}, err
+	}
+
+	file.Size, file.ReadCloser = size, stored
+	return file, nil
+}
+
+// tempFile is removed from disk when it is closed.
+type tempFile struct {
+	*os.File
+}
+
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil && !errors.Is(removeErr, os.ErrNotExist) {
+		err = removeErr
+	}
+
+	return err
+}
+
+// bindFiles maps files to File and []File properties of target struct by `form` tag.
+// Files which are not bound are closed.
+func bindFiles(files map[string][]File, v interface{}) {
+	target := reflect.ValueOf(v).Elem()
+
+	for _, field := range fileFields(target.Type()) {
+		if len(files[field.key]) == 0 {
+			continue
+		}
+
+		property := fieldByIndex(target, field.index)
+		if field.Type == fileType {
+			property.Set(reflect.ValueOf(files[field.key][0]))
+			files[field.key] = files[field.key][1:]
+		} else {
+			property.Set(reflect.ValueOf(files[field.key]))
+			delete(files, field.key)
+		}
+	}
+
+	closeFiles(files)
+}
+
+// closeFiles closes all files.
+func closeFiles(files map[string][]File) {
+	for _, list := range files {
+		for _, file := range list {
+			file.Close()
+		}
+	}
+}
+
+// closeRequestFiles closes files bound to properties of decoded request.
+func closeRequestFiles(v interface{}) {
+	target := reflect.Indirect(reflect.ValueOf(v))
+	if target.Kind() != reflect.Struct {
+		return
+	}
+
+	for _, field := range fileFields(target.Type()) {
+		property, ok := propertyByIndex(target, field.index)
+		if !ok {
+			continue
+		}
+
+		switch files := property.Interface().(type) {
+		case File:
+			if files.ReadCloser != nil {
+				files.Close()
+			}
+		case []File:
+			for _, file := range files {
+				if file.ReadCloser != nil {
+					file.Close()
+				}
+			}
+		}
+	}
+}
+
+// formError maps error of reading form body to error which can be returned to client.
+func formError(err error) error {
+	var coder StatusCoder
+	switch {
+	case errors.As(err, &coder):
+		return err
+	case errors.Is(err, errBodyTooLarge):
+		return jsonError(err)
+	default:
+		return InvalidArgument(fmt.Sprintf("malformed request body: %s", err.Error()))
+	}
+}
+
+// partError reports error of reading named part of multipart body.
+func partError(name string, err error) error {
+	if errors.Is(err, errBodyTooLarge) {
+		return Error{
+			Status:  http.StatusRequestEntityTooLarge,
+			Code:    statusCode(http.StatusRequestEntityTooLarge),
+			Message: fmt.Sprintf("part %q is too large", name),
+		}
+	}
+
+	return err
+}
+
+// formSchema returns schema of properties of struct type t with `form` tag. Files are described as binary strings.
+func formSchema(g *schemaGenerator, t reflect.Type) *Schema {
+	s := valuesObject(g, t, "form")
+	if s == nil {
+		s = &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	}
+
+	for _, field := range fileFields(t) {
+		property := &Schema{Type: "string", Format: "binary"}
+		if field.Type == fileSliceType {
+			property = &Schema{Type: "array", Items: property}
+		}
+
+		rules := parseRules(field.Tag.Get("validate"))
+		constrain(property, field.Type, rules)
+
+		s.Properties[field.key] = property
+		if hasRule(rules, "required") {
+			s.Required = append(s.Required, field.key)
+		}
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
+
+// hasFiles reports whether struct type t has properties bound to uploaded files.
+func hasFiles(t reflect.Type) bool {
+	return len(fileFields(t)) > 0
+}
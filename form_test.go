@@ -0,0 +1,261 @@
+package ferry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+type formRequest struct {
+	Name string `form:"name" validate:"required"`
+	Age  int    `form:"age"`
+}
+
+type uploadRequest struct {
+	Name   string `form:"name"`
+	Avatar File   `form:"avatar" validate:"required"`
+	Photos []File `form:"photos" validate:"max=2"`
+}
+
+func (t testService) SubmitForm(ctx context.Context, r *formRequest) (*testPayload, error) {
+	return &testPayload{Value: fmt.Sprintf("%s:%d", r.Name, r.Age)}, nil
+}
+
+func (t testService) Upload(ctx context.Context, r *uploadRequest) (*testPayload, error) {
+	avatar, err := io.ReadAll(r.Avatar)
+	if err != nil {
+		return nil, err
+	}
+
+	value := fmt.Sprintf("%s:%s:%s:%d:%s", r.Name, r.Avatar.Name, r.Avatar.ContentType, r.Avatar.Size, avatar)
+	for _, photo := range r.Photos {
+		value += ":" + photo.Name
+	}
+
+	if stored, ok := r.Avatar.ReadCloser.(*tempFile); ok {
+		value += ":" + stored.Name()
+	}
+
+	return &testPayload{Value: value}, nil
+}
+
+// multipartBody writes fields and files named "<field>/<file name>" to multipart body.
+func multipartBody(fields map[string]string, files map[string]string) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+
+	for key, content := range files {
+		field, name, _ := strings.Cut(key, "/")
+		part, _ := writer.CreateFormFile(field, name)
+		io.WriteString(part, content)
+	}
+	writer.Close()
+
+	return body, writer.FormDataContentType()
+}
+
+func TestForm(t *testing.T) {
+	svc := testService{}
+	router := NewRouter(WithFormLimits(FormLimits{MaxPartSize: 16}))
+	router.Register(Procedure(svc.SubmitForm), Procedure(svc.Upload))
+
+	t.Run("decodes URL encoded form", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/SubmitForm", strings.NewReader(url.Values{"name": {"John"}, "age": {"30"}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusOK || rr.Body.String() != `{"value":"John:30"}` {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("validates form", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/SubmitForm", strings.NewReader("age=30"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(rr, r)
+
+		expected := `{"error":"validation failed","code":"invalid_argument","fields":[{"field":"name","rule":"required","message":"is required"}]}`
+		if rr.Code != http.StatusUnprocessableEntity || rr.Body.String() != expected {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("decodes multipart form with files", func(t *testing.T) {
+		body, contentType := multipartBody(
+			map[string]string{"name": "John"},
+			map[string]string{"avatar/me.png": "avatar", "photos/1.png": "one"},
+		)
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Upload", body)
+		r.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(rr, r)
+
+		expected := `{"value":"John:me.png:application/octet-stream:6:avatar:1.png"}`
+		if rr.Code != http.StatusOK || rr.Body.String() != expected {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("stores files exceeding memory on disk", func(t *testing.T) {
+		router := NewRouter(WithFormLimits(FormLimits{MaxMemory: 2}))
+		router.Register(Procedure(svc.Upload))
+
+		body, contentType := multipartBody(nil, map[string]string{"avatar/me.png": "avatar"})
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Upload", body)
+		r.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(rr, r)
+
+		var response testPayload
+		if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+			t.Fatalf("unexpected response, got %s", rr.Body.String())
+		}
+
+		parts := strings.SplitN(response.Value, ":", 6)
+		if len(parts) != 6 || parts[4] != "avatar" {
+			t.Fatalf("expected file stored on disk, got %s", response.Value)
+		}
+
+		if _, err := os.Stat(parts[5]); !os.IsNotExist(err) {
+			t.Errorf("expected temporary file to be removed, got %v", err)
+		}
+	})
+
+	t.Run("rejects too large part", func(t *testing.T) {
+		body, contentType := multipartBody(nil, map[string]string{"avatar/me.png": strings.Repeat("a", 17)})
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Upload", body)
+		r.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(rr, r)
+
+		expected := `{"error":"part \"avatar\" is too large","code":"request_entity_too_large"}`
+		if rr.Code != http.StatusRequestEntityTooLarge || rr.Body.String() != expected {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("rejects too large body", func(t *testing.T) {
+		router := NewRouter(WithFormLimits(FormLimits{MaxBodySize: 16}))
+		router.Register(Procedure(svc.SubmitForm))
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/SubmitForm", strings.NewReader("name="+strings.Repeat("a", 16)))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status code %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+		}
+	})
+
+	t.Run("removes stored files when body is too large", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("TMPDIR", dir)
+
+		router := NewRouter(WithFormLimits(FormLimits{MaxMemory: 2, MaxBodySize: 1024}))
+		router.Register(Procedure(svc.Upload))
+
+		body, contentType := multipartBody(nil, map[string]string{"avatar/me.png": "avatar"})
+		body.WriteString(strings.Repeat("epilogue", 1024))
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Upload", body)
+		r.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("expected status code %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+		}
+
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("expected temporary files to be removed, got %v", entries)
+		}
+	})
+
+	t.Run("describes multipart schema", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		OpenAPI(router, OpenAPIInfo{})(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+		var document map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &document); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		operation := lookup(document, "paths", "/Upload", "post", "requestBody", "content")
+		if format := lookup(operation, "multipart/form-data", "schema", "properties", "avatar", "format"); format != "binary" {
+			t.Errorf("unexpected avatar schema, got %v", operation)
+		}
+
+		if urlEncoded := lookup(operation, "application/x-www-form-urlencoded"); urlEncoded != nil {
+			t.Errorf("files can not be sent URL encoded, got %v", urlEncoded)
+		}
+
+		if required := lookup(operation, "multipart/form-data", "schema", "required"); fmt.Sprint(required) != "[avatar]" {
+			t.Errorf("unexpected required fields, got %v", required)
+		}
+	})
+
+	t.Run("binds nested and embedded properties", func(t *testing.T) {
+		type attachment struct {
+			Document File `form:"document" validate:"required"`
+		}
+		type nestedForm struct {
+			attachment
+			Address struct {
+				City string `form:"city" validate:"required"`
+			} `form:"address"`
+		}
+
+		var received nestedForm
+		router := NewRouter()
+		router.Register(Procedure(func(ctx context.Context, r *nestedForm) (*empty, error) {
+			received = *r
+			content, _ := io.ReadAll(r.Document)
+			received.Document.Name += ":" + string(content)
+			return &empty{}, nil
+		}, Name("Nested")))
+
+		rr := httptest.NewRecorder()
+		body, contentType := multipartBody(map[string]string{"address.city": "Vilnius"}, map[string]string{"document/a.txt": "text"})
+		r := httptest.NewRequest(http.MethodPost, "/Nested", body)
+		r.Header.Set("Content-Type", contentType)
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusOK || received.Address.City != "Vilnius" || received.Document.Name != "a.txt:text" {
+			t.Errorf("unexpected request, got %d %s %+v", rr.Code, rr.Body.String(), received)
+		}
+
+		rr = httptest.NewRecorder()
+		OpenAPI(router, OpenAPIInfo{})(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+		var document map[string]interface{}
+		if err := json.Unmarshal(rr.Body.Bytes(), &document); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		schema := lookup(document, "paths", "/Nested", "post", "requestBody", "content", "multipart/form-data", "schema")
+		if lookup(schema, "properties", "address.city", "type") != "string" || lookup(schema, "properties", "document", "format") != "binary" {
+			t.Errorf("unexpected schema, got %v", schema)
+		}
+
+		if required := lookup(schema, "required"); fmt.Sprint(required) != "[address.city document]" {
+			t.Errorf("unexpected required fields, got %v", required)
+		}
+	})
+}
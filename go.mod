@@ -3,7 +3,10 @@ module github.com/damejeras/ferry
 go 1.18
 
 require (
-	github.com/fatih/structtag v1.2.0
+	github.com/fxamacker/cbor/v2 v2.5.0
 	github.com/go-chi/chi/v5 v5.0.7
-	github.com/mitchellh/reflectwalk v1.0.2
+	github.com/gorilla/websocket v1.5.0
+	google.golang.org/protobuf v1.34.0
 )
+
+require github.com/x448/float16 v0.8.4 // indirect
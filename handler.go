@@ -4,11 +4,12 @@ import (
 	"net/http"
 )
 
-// Handler can only be acquired from helper methods (Procedure, Stream).
+// Handler can only be acquired from helper methods (Procedure, Stream, BidiStream, ClientStream).
 type Handler interface {
 	http.Handler
 
 	build(*mux)
+	describe() meta
 }
 
 type procedureHandler struct {
@@ -30,3 +31,23 @@ type streamHandler struct {
 
 func (h *streamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) { h.http(w, r) }
 func (h *streamHandler) build(m *mux)                                     { h.http = h.builder(m) }
+
+type bidiHandler struct {
+	builder func(m *mux) http.HandlerFunc
+	http    func(http.ResponseWriter, *http.Request)
+
+	meta
+}
+
+func (h *bidiHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) { h.http(w, r) }
+func (h *bidiHandler) build(m *mux)                                     { h.http = h.builder(m) }
+
+type clientStreamHandler struct {
+	builder func(m *mux) http.HandlerFunc
+	http    func(http.ResponseWriter, *http.Request)
+
+	meta
+}
+
+func (h *clientStreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) { h.http(w, r) }
+func (h *clientStreamHandler) build(m *mux)                                     { h.http = h.builder(m) }
@@ -0,0 +1,97 @@
+package ferry
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ProcedureInfo describes Procedure which is being invoked.
+type ProcedureInfo struct {
+	Name     string
+	Path     string
+	Request  reflect.Type
+	Response reflect.Type
+}
+
+// Interceptor wraps Procedure invocation. It receives pointer to decoded request and must call next
+// to invoke the procedure, or return early to short-circuit it. Response returned by next can be replaced.
+type Interceptor func(ctx context.Context, info ProcedureInfo, req any, next func(context.Context, any) (any, error)) (any, error)
+
+// procedureInfo creates ProcedureInfo from handler meta.
+func procedureInfo(mt meta) ProcedureInfo {
+	return ProcedureInfo{
+		Name:     mt.name,
+		Path:     mt.path,
+		Request:  mt.request,
+		Response: mt.response,
+	}
+}
+
+// intercept wraps call with interceptors. First interceptor is the outermost one.
+func intercept(interceptors []Interceptor, info ProcedureInfo, call func(context.Context, any) (any, error)) func(context.Context, any) (any, error) {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], call
+		call = func(ctx context.Context, req any) (any, error) {
+			return interceptor(ctx, info, req, next)
+		}
+	}
+
+	return call
+}
+
+// StreamInfo describes Stream which is being opened.
+type StreamInfo struct {
+	Name    string
+	Path    string
+	Request reflect.Type
+	Payload reflect.Type
+}
+
+// StreamInterceptor is called before Stream is opened. It receives pointer to decoded request and can reject
+// the stream by returning an error. Returned EventInterceptor, if not nil, receives every event of the stream.
+type StreamInterceptor func(ctx context.Context, info StreamInfo, req any) (EventInterceptor, error)
+
+// EventInterceptor receives Event before it is written to the stream. It can return the same or transformed Event,
+// nil to skip it or an error to terminate the stream. Error is sent to client as "error" event.
+type EventInterceptor func(event any) (any, error)
+
+// streamInfo creates StreamInfo from handler meta.
+func streamInfo(mt meta) StreamInfo {
+	return StreamInfo{
+		Name:    mt.name,
+		Path:    mt.path,
+		Request: mt.request,
+		Payload: mt.response,
+	}
+}
+
+// interceptStream calls stream interceptors in the given order and combines returned event interceptors.
+func interceptStream(ctx context.Context, interceptors []StreamInterceptor, info StreamInfo, req any) (EventInterceptor, error) {
+	filters := make([]EventInterceptor, 0, len(interceptors))
+	for _, interceptor := range interceptors {
+		filter, err := interceptor(ctx, info, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if filter != nil {
+			filters = append(filters, filter)
+		}
+	}
+
+	return func(event any) (any, error) {
+		for _, filter := range filters {
+			var err error
+			if event, err = filter(event); err != nil || event == nil {
+				return nil, err
+			}
+		}
+
+		if _, ok := event.(streamEvent); !ok {
+			return nil, fmt.Errorf("%q event interceptor returned %T, Event expected", info.Name, event)
+		}
+
+		return event, nil
+	}, nil
+}
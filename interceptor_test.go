@@ -0,0 +1,181 @@
+package ferry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestInterceptors(t *testing.T) {
+	svc := testService{}
+	calls := make([]string, 0)
+
+	router := NewRouter(WithInterceptors(
+		func(ctx context.Context, info ProcedureInfo, req any, next func(context.Context, any) (any, error)) (any, error) {
+			calls = append(calls, "outer:"+info.Name)
+			if info.Path != "/TestProcedureWithParams" || info.Request != reflect.TypeOf(jsonRequest{}) || info.Response != reflect.TypeOf(testPayload{}) {
+				t.Errorf("unexpected info, got %+v", info)
+			}
+
+			if req.(*jsonRequest).Value == "forbidden" {
+				return nil, PermissionDenied("forbidden")
+			}
+
+			return next(ctx, req)
+		},
+		func(ctx context.Context, info ProcedureInfo, req any, next func(context.Context, any) (any, error)) (any, error) {
+			calls = append(calls, "inner:"+info.Name)
+			res, err := next(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			return &testPayload{Value: res.(*testPayload).Value + "_intercepted"}, nil
+		},
+	))
+	router.Register(Procedure(svc.TestProcedureWithParams))
+
+	t.Run("wraps procedure", func(t *testing.T) {
+		calls = calls[:0]
+		body, _ := json.Marshal(jsonRequest{Value: "test_data"})
+		request := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		var response testPayload
+		if err := json.NewDecoder(recorder.Body).Decode(&response); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+
+		if response.Value != "test_data_intercepted" {
+			t.Errorf("unexpected response, got %+v", response)
+		}
+
+		if !reflect.DeepEqual(calls, []string{"outer:TestProcedureWithParams", "inner:TestProcedureWithParams"}) {
+			t.Errorf("unexpected calls, got %v", calls)
+		}
+	})
+
+	t.Run("short-circuits procedure", func(t *testing.T) {
+		calls = calls[:0]
+		body, _ := json.Marshal(jsonRequest{Value: "forbidden"})
+		request := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", bytes.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+
+		router.ServeHTTP(recorder, request)
+
+		if recorder.Code != http.StatusForbidden {
+			t.Errorf("expected status %d, got %d", http.StatusForbidden, recorder.Code)
+		}
+
+		if !reflect.DeepEqual(calls, []string{"outer:TestProcedureWithParams"}) {
+			t.Errorf("unexpected calls, got %v", calls)
+		}
+	})
+}
+
+func (s testService) StreamThreeEvents(ctx context.Context, r *queryRequest) (<-chan Event[testPayload], error) {
+	c := make(chan Event[testPayload])
+
+	go func() {
+		defer close(c)
+
+		for i := 1; i <= 3; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- Event[testPayload]{ID: strconv.Itoa(i), Payload: &testPayload{Value: r.Value}}:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+func TestStreamInterceptors(t *testing.T) {
+	svc := testService{}
+
+	router := NewRouter(WithStreamInterceptors(
+		func(ctx context.Context, info StreamInfo, req any) (EventInterceptor, error) {
+			if info.Name != "StreamThreeEvents" || info.Payload != reflect.TypeOf(testPayload{}) {
+				t.Errorf("unexpected info, got %+v", info)
+			}
+
+			if req.(*queryRequest).Value == "forbidden" {
+				return nil, PermissionDenied("forbidden")
+			}
+
+			return nil, nil
+		},
+		func(ctx context.Context, info StreamInfo, req any) (EventInterceptor, error) {
+			value := req.(*queryRequest).Value
+
+			return func(event any) (any, error) {
+				e := event.(Event[testPayload])
+				switch {
+				case e.ID == "2" && value == "terminate":
+					return nil, Unavailable("going away", 0)
+				case e.ID == "2":
+					return nil, nil
+				default:
+					return Event[testPayload]{ID: e.ID, Payload: &testPayload{Value: e.Payload.Value + "_intercepted"}}, nil
+				}
+			}, nil
+		},
+	))
+	router.Register(Stream(svc.StreamThreeEvents))
+
+	tests := []struct {
+		name   string
+		value  string
+		status int
+		body   string
+	}{
+		{
+			name:   "rejects stream",
+			value:  "forbidden",
+			status: http.StatusForbidden,
+			body:   `{"error":"forbidden","code":"permission_denied"}`,
+		},
+		{
+			name:   "transforms and skips events",
+			value:  "test",
+			status: http.StatusOK,
+			body: "event: keep-alive\n\n" +
+				"id: 1\nevent: testPayload\ndata: {\"value\":\"test_intercepted\"}\n\n" +
+				"id: 3\nevent: testPayload\ndata: {\"value\":\"test_intercepted\"}\n\n",
+		},
+		{
+			name:   "terminates stream",
+			value:  "terminate",
+			status: http.StatusOK,
+			body: "event: keep-alive\n\n" +
+				"id: 1\nevent: testPayload\ndata: {\"value\":\"terminate_intercepted\"}\n\n" +
+				"event: error\ndata: {\"error\":\"going away\",\"code\":\"unavailable\"}\n\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/StreamThreeEvents?value="+tt.value, nil))
+
+			if recorder.Code != tt.status {
+				t.Errorf("expected status %d, got %d", tt.status, recorder.Code)
+			}
+
+			if body := recorder.Body.String(); body != tt.body {
+				t.Errorf("unexpected body, got %q", body)
+			}
+		})
+	}
+}
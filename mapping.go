@@ -1,116 +1,211 @@
 package ferry
 
 import (
+	"encoding"
 	"fmt"
 	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
 
-	"github.com/fatih/structtag"
-	"github.com/mitchellh/reflectwalk"
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
 )
 
-// jsonMapping walks over the target struct and returns a map of json tags to their corresponding go types.
-func jsonMapping(v interface{}) (map[string]string, error) {
-	mapping := make(bodyMap)
+// queryMapping walks over the target struct and returns a map of query keys to names of their types.
+func queryMapping(v interface{}) (map[string]string, error) {
+	mapping := make(map[string]string)
 
-	if err := reflectwalk.Walk(v, mapping); err != nil {
-		return nil, err
+	for _, field := range bindingFields(reflect.TypeOf(v), "query") {
+		typ, ok := valueType(field.Type)
+		if !ok {
+			return nil, fmt.Errorf("type %q is not supported as query param", field.Type)
+		}
+		mapping[field.key] = typ
 	}
 
 	return mapping, nil
 }
 
-// queryMapping walks over the target struct and returns a map of query tags to their corresponding go types.
-func queryMapping(v interface{}) (map[string]string, error) {
-	mapping := make(queryMap)
+// bindingField is a property of request struct bound from query or form values.
+type bindingField struct {
+	reflect.StructField
+
+	// key is the name of the value, keys of nested struct properties are joined with dots
+	key string
+	// index is the index sequence of property in request struct
+	index []int
+	// path is the sequence of JSON names leading to property, it is empty if property is not encoded to JSON
+	path []string
+}
 
-	if err := reflectwalk.Walk(v, mapping); err != nil {
-		return nil, err
+// bindingFields returns properties of struct type t with given tag. Properties of nested structs are prefixed
+// with key of their parent, embedded structs without tag are flattened. Properties bound to files are skipped.
+// Types other than structs have no properties.
+func bindingFields(t reflect.Type, tag string) []bindingField {
+	if indirectType(t).Kind() != reflect.Struct {
+		return nil
 	}
 
-	return mapping, nil
+	return appendBindingFields(nil, indirectType(t), tag, false, "", nil, []string{}, make(map[reflect.Type]bool))
+}
+
+// fileFields returns properties of struct type t bound to uploaded files by `form` tag.
+// Keys of the properties are formed the same way bindingFields forms them.
+func fileFields(t reflect.Type) []bindingField {
+	if indirectType(t).Kind() != reflect.Struct {
+		return nil
+	}
+
+	return appendBindingFields(nil, indirectType(t), "form", true, "", nil, []string{}, make(map[reflect.Type]bool))
 }
 
-type bodyMap map[string]string
-type queryMap map[string]string
+// appendBindingFields appends properties of struct type t to fields. Properties bound to files are appended
+// instead of the others if files is true.
+func appendBindingFields(fields []bindingField, t reflect.Type, tag string, files bool, prefix string, index []int, path []string, seen map[reflect.Type]bool) []bindingField {
+	if seen[t] {
+		// self-referencing structs are bound once
+		return fields
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+		fieldPath := jsonPath(path, field)
+
+		if !field.IsExported() {
+			// properties of unexported embedded structs are promoted, unless struct is embedded by pointer
+			// which can not be allocated
+			if field.Anonymous && field.Type.Kind() == reflect.Struct && isNested(field.Type) {
+				fields = appendBindingFields(fields, field.Type, tag, files, prefix, fieldIndex, fieldPath, seen)
+			}
+			continue
+		}
+
+		key, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if key == "" || key == "-" {
+			if _, tagged := field.Tag.Lookup(tag); !tagged && field.Anonymous && isNested(field.Type) {
+				fields = appendBindingFields(fields, indirectType(field.Type), tag, files, prefix, fieldIndex, fieldPath, seen)
+			}
+			continue
+		}
+
+		if isNested(field.Type) && !isFile(field.Type) {
+			fields = appendBindingFields(fields, indirectType(field.Type), tag, files, prefix+key+".", fieldIndex, fieldPath, seen)
+			continue
+		}
+
+		if isFile(field.Type) != files {
+			continue
+		}
+
+		fields = append(fields, bindingField{StructField: field, key: prefix + key, index: fieldIndex, path: fieldPath})
+	}
 
-func (s bodyMap) Struct(value reflect.Value) error  { return nil }
-func (s queryMap) Struct(value reflect.Value) error { return nil }
+	return fields
+}
 
-func (s bodyMap) StructField(field reflect.StructField, value reflect.Value) error {
-	tags, err := structtag.Parse(string(field.Tag))
-	if err != nil {
-		return err
+// jsonPath returns path of JSON names leading to property. Embedded structs are flattened by encoding/json.
+func jsonPath(path []string, field reflect.StructField) []string {
+	if path == nil {
+		return nil
 	}
 
-	jsonTag, err := tags.Get("json")
-	if err != nil {
-		// skip fields without json tag
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch {
+	case name == "-":
 		return nil
+	case name == "" && field.Anonymous && isNested(field.Type):
+		return append([]string{}, path...)
+	case name == "":
+		name = field.Name
 	}
 
-	switch field.Type.Kind() {
+	return append(append([]string{}, path...), name)
+}
+
+// isNested reports whether properties of type t are bound by their own keys.
+func isNested(t reflect.Type) bool {
+	_, ok := valueType(t)
+	return !ok && indirectType(t).Kind() == reflect.Struct
+}
+
+// valueType returns name of the type of value bound to property of type t,
+// or false if type can not be bound from string values.
+func valueType(t reflect.Type) (string, bool) {
+	switch {
+	case t == durationType:
+		return "duration", true
+	case t == timeType:
+		return "date-time", true
+	case t.Kind() != reflect.Ptr && reflect.PtrTo(t).Implements(textUnmarshalerType):
+		return "string", true
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return valueType(t.Elem())
 	case reflect.String:
-		s[jsonTag.Name] = "string"
+		return "string", true
 	case reflect.Bool:
-		s[jsonTag.Name] = "boolean"
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		s[jsonTag.Name] = "integer"
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		s[jsonTag.Name] = "integer"
+		return "boolean", true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer", true
 	case reflect.Float32, reflect.Float64:
-		s[jsonTag.Name] = "float"
+		return "float", true
 	case reflect.Slice:
-		if field.Type.Elem().Kind() == reflect.Uint8 {
-			s[jsonTag.Name] = "binary"
-		} else {
-			s[jsonTag.Name] = "array"
-		}
-	case reflect.Array:
-		s[jsonTag.Name] = "array"
-	case reflect.Struct:
-		if field.Type.Name() == "Time" {
-			s[jsonTag.Name] = "date-time"
-		} else {
-			s[jsonTag.Name] = "object"
-		}
-	case reflect.Map, reflect.Interface:
-		s[jsonTag.Name] = "object"
-	case reflect.Ptr:
-		if field.Type.Elem().Kind() == reflect.Struct {
-			s[jsonTag.Name] = "object"
+		if t.Elem().Kind() == reflect.Uint8 || t.Elem().Kind() == reflect.Slice {
+			return "", false
 		}
+		elem, ok := valueType(t.Elem())
+		return elem + "[]", ok
 	default:
-		return fmt.Errorf("kind %q is not supported as json param", field.Type.Kind())
+		return "", false
 	}
-
-	return nil
 }
 
-func (s queryMap) StructField(field reflect.StructField, value reflect.Value) error {
-	tags, err := structtag.Parse(string(field.Tag))
-	if err != nil {
-		return err
+// querySchema describes query parameter of type t. Durations and types implementing encoding.TextUnmarshaler
+// are sent as strings, slices as repeated parameters.
+func querySchema(generator *schemaGenerator, t reflect.Type) *Schema {
+	t = indirectType(t)
+
+	switch {
+	case t == durationType:
+		return &Schema{Type: "string", Format: "duration"}
+	case t != timeType && isText(t):
+		return &Schema{Type: "string"}
+	case t.Kind() == reflect.Slice:
+		return &Schema{Type: "array", Items: querySchema(generator, t.Elem())}
+	default:
+		return generator.schema(t)
 	}
+}
 
-	queryTag, err := tags.Get("query")
-	if err != nil {
-		// skip fields without query tag
+// valuesObject describes values bound to struct type t by given tag as properties of object. Properties are
+// constrained by their validation rules. It returns nil if type has no properties with the tag.
+func valuesObject(generator *schemaGenerator, t reflect.Type, tag string) *Schema {
+	fields := bindingFields(t, tag)
+	if len(fields) == 0 {
 		return nil
 	}
 
-	kind := field.Type.Kind()
-	switch kind {
-	case reflect.String:
-		s[queryTag.Name] = "string"
-	case reflect.Bool:
-		s[queryTag.Name] = "boolean"
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		s[queryTag.Name] = "integer"
-	case reflect.Float32, reflect.Float64:
-		s[queryTag.Name] = "float"
-	default:
-		return fmt.Errorf("kind %q is not supported as query param", kind)
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for _, field := range fields {
+		property := querySchema(generator, field.Type)
+		rules := parseRules(field.Tag.Get("validate"))
+		constrain(property, field.Type, rules)
+
+		s.Properties[field.key] = property
+		if hasRule(rules, "required") {
+			s.Required = append(s.Required, field.key)
+		}
 	}
+	sort.Strings(s.Required)
 
-	return nil
+	return s
 }
@@ -20,49 +20,6 @@ type invalidQueryRequest struct {
 	Data []byte `query:"data"`
 }
 
-func TestJSONMapping(t *testing.T) {
-	testCases := []struct {
-		name     string
-		input    interface{}
-		expected map[string]string
-	}{
-		{
-			name:  "exotic",
-			input: exoticJSONRequest{},
-			expected: map[string]string{
-				"number": "integer",
-				"float":  "float",
-				"data":   "binary",
-			},
-		},
-		{
-			name:     "empty",
-			input:    empty{},
-			expected: map[string]string{},
-		},
-		{
-			name:  "string",
-			input: testPayload{},
-			expected: map[string]string{
-				"value": "string",
-			},
-		},
-	}
-
-	for _, testCase := range testCases {
-		t.Run(testCase.name, func(t *testing.T) {
-			mapping, err := jsonMapping(testCase.input)
-			if err != nil {
-				t.Errorf("unexpected error: %s", err)
-			}
-
-			if !reflect.DeepEqual(mapping, testCase.expected) {
-				t.Errorf("unexpected mapping, got %v", mapping)
-			}
-		})
-	}
-}
-
 func TestQueryMapping(t *testing.T) {
 	t.Run("test mapping", func(t *testing.T) {
 		testCases := []struct {
@@ -87,6 +44,24 @@ func TestQueryMapping(t *testing.T) {
 					"value": "string",
 				},
 			},
+			{
+				name:  "binding",
+				input: bindingRequest{},
+				expected: map[string]string{
+					"page":        "integer",
+					"limit":       "integer",
+					"offset":      "integer",
+					"id":          "integer[]",
+					"since":       "date-time",
+					"timeout":     "duration",
+					"address":     "string",
+					"filter.name": "string",
+					"filter.tag":  "string[]",
+					"sort.name":   "string",
+					"sort.tag":    "string[]",
+					"optional":    "boolean",
+				},
+			},
 			{
 				name:  "exotic",
 				input: exoticQueryRequest{},
@@ -9,33 +9,145 @@ import (
 
 // meta contains information about service method.
 type meta struct {
-	name  string
-	body  map[string]string
-	query map[string]string
+	name string
+	// method and path are used to register handler in Router.
+	method string
+	path   string
+	// also are additional methods handler is registered with.
+	also []string
+
+	request  reflect.Type
+	response reflect.Type
+	// body is the schema of request body, it is nil if request has no json fields.
+	body *Schema
+	// form is the schema of form request body, it is nil if request has no form fields.
+	form *Schema
+	// payload is the schema of response or stream message.
+	payload *Schema
+	query   *Schema
+	// stream overrides router-wide configuration of Stream handler.
+	stream streamConfig
+}
+
+// buildMeta uses reflection to describe service method with given name.
+// Response is the type of procedure response or stream message.
+func buildMeta(name string, request, response reflect.Type) (meta, error) {
+	m := meta{
+		name:     name,
+		request:  request,
+		response: response,
+	}
+
+	if hasJSONFields(m.request) {
+		m.body = rootSchema(m.request)
+	}
+	if hasFormFields(m.request) {
+		m.form = formSchema(newSchemaGenerator("#/$defs/"), m.request)
+	}
+	m.payload = rootSchema(m.response)
+
+	var err error
+	// unsupported query properties are reported on registration
+	if _, err = queryMapping(reflect.New(request).Interface()); err != nil {
+		return meta{}, fmt.Errorf("can not create query mapping: %w", err)
+	}
+	m.query = valuesObject(newSchemaGenerator("#/$defs/"), m.request, "query")
+
+	// default values are converted upfront, so invalid defaults are reported on registration
+	for _, tag := range []string{"query", "form"} {
+		if err := checkDefaults(request, tag); err != nil {
+			return meta{}, err
+		}
+	}
+
+	// compile validators upfront, so invalid rules are reported on registration
+	for _, tag := range []string{"json", "query", "form"} {
+		if _, err := compileValidator(reflect.PtrTo(request), tag); err != nil {
+			return meta{}, fmt.Errorf("can not create validator: %w", err)
+		}
+	}
+
+	return m, nil
 }
 
-// buildMeta uses reflection to determine service name and method name.
-func buildMeta(function, request interface{}) (meta, error) {
+// describe returns handler metadata. It is promoted to handlers which embed meta.
+func (m meta) describe() meta { return m }
+
+// methods returns all HTTP methods handler is registered with.
+func (m meta) methods() []string {
+	return append([]string{m.method}, m.also...)
+}
+
+// setDefaults sets HTTP method and path which were not set by options.
+func (m *meta) setDefaults(method string) {
+	if m.method == "" {
+		m.method = method
+	}
+
+	if m.path == "" {
+		m.path = "/" + m.name
+	}
+}
+
+// handlerMeta describes handler of function fn and applies options to it. Handler is named after the function,
+// unless Name option is set. It panics if request type is not supported.
+func handlerMeta(fn interface{}, request, response reflect.Type, options []func(*meta)) meta {
+	name, err := funcName(fn)
+	if err != nil {
+		panic(err)
+	}
+
+	mt, err := buildMeta(name, request, response)
+	if err != nil {
+		panic(err)
+	}
+
+	for i := range options {
+		options[i](&mt)
+	}
+
+	return mt
+}
+
+// funcName uses reflection to determine method name of the function.
+func funcName(function interface{}) (string, error) {
 	name := runtime.FuncForPC(reflect.ValueOf(function).Pointer()).Name()
 
 	// -fm is a suffix for functions that have receiver.
 	nameParts := strings.Split(strings.TrimSuffix(name, "-fm"), ".")
 	if len(nameParts) < 2 {
-		return meta{}, fmt.Errorf("can not use %q as handler", name)
+		return "", fmt.Errorf("can not use %q as handler", name)
 	}
 
-	m := meta{
-		name: nameParts[len(nameParts)-1],
+	return nameParts[len(nameParts)-1], nil
+}
+
+// hasJSONFields reports whether struct type has fields with json tag.
+func hasJSONFields(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
 	}
 
-	var err error
-	if m.body, err = jsonMapping(request); err != nil {
-		return meta{}, fmt.Errorf("can not create json mapping: %w", err)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup("json"); ok {
+			return true
+		}
+
+		if field.Anonymous && hasJSONFields(field.Type) {
+			return true
+		}
 	}
 
-	if m.query, err = queryMapping(request); err != nil {
-		return meta{}, fmt.Errorf("can not create query mapping: %w", err)
+	return false
+}
+
+// hasFormFields reports whether struct has properties with `form` tag, including properties of nested
+// and embedded structs.
+func hasFormFields(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
 	}
 
-	return m, nil
+	return len(bindingFields(t, "form")) > 0 || hasFiles(t)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"reflect"
 	"testing"
+	"time"
 )
 
 type testMeta struct{}
@@ -16,72 +17,80 @@ func testProc(ctx context.Context, _ *empty) (*empty, error) {
 	return &empty{}, nil
 }
 
-func TestBuildMeta(t *testing.T) {
+func TestFuncName(t *testing.T) {
 	t.Run("handles anonymous functions", func(t *testing.T) {
-		m, err := buildMeta(func(ctx context.Context, _ *empty) (*empty, error) {
+		name, err := funcName(func(ctx context.Context, _ *empty) (*empty, error) {
 			return &empty{}, nil
-		}, empty{})
+		})
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		expected := meta{
-			name:  "1",
-			body:  make(map[string]string),
-			query: make(map[string]string),
+		if name != "1" {
+			t.Errorf("got %q", name)
 		}
 
-		if !reflect.DeepEqual(m, expected) {
-			t.Errorf("got %+v", m)
-		}
-
-		m, err = buildMeta(func(ctx context.Context, _ *empty) (*empty, error) {
+		name, err = funcName(func(ctx context.Context, _ *empty) (*empty, error) {
 			return &empty{}, nil
-		}, empty{})
+		})
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		expected = meta{
-			name:  "2",
-			body:  make(map[string]string),
-			query: make(map[string]string),
-		}
-
-		if !reflect.DeepEqual(m, expected) {
-			t.Errorf("got %+v", m)
+		if name != "2" {
+			t.Errorf("got %q", name)
 		}
 	})
 
 	t.Run("handles function with pointer receiver", func(t *testing.T) {
 		svc := new(testMeta)
-		m, err := buildMeta(svc.TestProcedure, empty{})
+		name, err := funcName(svc.TestProcedure)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 
-		expected := meta{
-			name:  "TestProcedure",
-			body:  make(map[string]string),
-			query: make(map[string]string),
-		}
-
-		if !reflect.DeepEqual(m, expected) {
-			t.Errorf("got %+v", m)
+		if name != "TestProcedure" {
+			t.Errorf("got %q", name)
 		}
 	})
 
 	t.Run("handles function with value receiver", func(t *testing.T) {
 		svc := testService{}
-		m, err := buildMeta(svc.StreamOneEvent, empty{})
+		name, err := funcName(svc.StreamOneEvent)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if name != "StreamOneEvent" {
+			t.Errorf("got %q", name)
+		}
+	})
+
+	t.Run("handles function without receiver", func(t *testing.T) {
+		name, err := funcName(testProc)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		if name != "testProc" {
+			t.Errorf("got %q", name)
+		}
+	})
+}
+
+func TestBuildMeta(t *testing.T) {
+	t.Run("describes procedure", func(t *testing.T) {
+		m, err := buildMeta("TestProcedure", reflect.TypeOf(jsonRequest{}), reflect.TypeOf(testPayload{}))
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 
 		expected := meta{
-			name:  "StreamOneEvent",
-			body:  make(map[string]string),
-			query: make(map[string]string),
+			name:     "TestProcedure",
+			request:  reflect.TypeOf(jsonRequest{}),
+			response: reflect.TypeOf(testPayload{}),
+			body:     rootSchema(reflect.TypeOf(jsonRequest{})),
+			payload:  rootSchema(reflect.TypeOf(testPayload{})),
 		}
 
 		if !reflect.DeepEqual(m, expected) {
@@ -89,20 +98,71 @@ func TestBuildMeta(t *testing.T) {
 		}
 	})
 
-	t.Run("handles function without receiver", func(t *testing.T) {
-		m, err := buildMeta(testProc, empty{})
+	t.Run("describes stream", func(t *testing.T) {
+		m, err := buildMeta("StreamOneEvent", reflect.TypeOf(queryRequest{}), reflect.TypeOf(testPayload{}))
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 
 		expected := meta{
-			name:  "testProc",
-			body:  make(map[string]string),
-			query: make(map[string]string),
+			name:     "StreamOneEvent",
+			request:  reflect.TypeOf(queryRequest{}),
+			response: reflect.TypeOf(testPayload{}),
+			payload:  rootSchema(reflect.TypeOf(testPayload{})),
+			query: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"value": {Type: "string"}},
+			},
 		}
 
 		if !reflect.DeepEqual(m, expected) {
 			t.Errorf("got %+v", m)
 		}
 	})
+
+	t.Run("describes query constraints", func(t *testing.T) {
+		type constrainedQuery struct {
+			Name  string `query:"name" validate:"required,oneof=a b"`
+			Limit int    `query:"limit" validate:"min=1"`
+		}
+
+		m, err := buildMeta("Constrained", reflect.TypeOf(constrainedQuery{}), reflect.TypeOf(empty{}))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(m.query.Required, []string{"name"}) || len(m.query.Properties["name"].Enum) != 2 ||
+			m.query.Properties["limit"].Minimum == nil || *m.query.Properties["limit"].Minimum != 1 {
+			t.Errorf("unexpected query schema, got %+v", m.query)
+		}
+	})
+
+	t.Run("rejects invalid query", func(t *testing.T) {
+		if _, err := buildMeta("Invalid", reflect.TypeOf(invalidQueryRequest{}), reflect.TypeOf(empty{})); err == nil {
+			t.Error("expected error")
+		}
+	})
+
+	t.Run("checks default values", func(t *testing.T) {
+		type validDefaults struct {
+			Wait time.Duration `query:"wait" default:"1m"`
+			Tags []int         `form:"tags" default:"1,2"`
+		}
+		type invalidQueryDefault struct {
+			Limit int `query:"limit" default:"ten"`
+		}
+		type invalidFormDefault struct {
+			Tags []int `form:"tags" default:"1,two"`
+		}
+
+		if _, err := buildMeta("ValidDefaults", reflect.TypeOf(validDefaults{}), reflect.TypeOf(empty{})); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		for _, request := range []reflect.Type{reflect.TypeOf(invalidQueryDefault{}), reflect.TypeOf(invalidFormDefault{})} {
+			if _, err := buildMeta("InvalidDefault", request, reflect.TypeOf(empty{})); err == nil {
+				t.Errorf("expected error of %s", request)
+			}
+		}
+	})
 }
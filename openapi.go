@@ -0,0 +1,282 @@
+package ferry
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// OpenAPIInfo holds general information about API described by OpenAPI document.
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// OpenAPI walks chi.Router routing tree and creates http.HandlerFunc
+// that will return OpenAPI 3.1 document describing ferry endpoints.
+func OpenAPI(router chi.Router, info OpenAPIInfo) http.HandlerFunc {
+	codecs := defaultCodecs
+	if m, ok := router.(*mux); ok {
+		codecs = m.codecs
+	}
+
+	generator := newSchemaGenerator("#/components/schemas/")
+	errorResponse := openAPIResponse{
+		Description: "Error",
+		Content:     errorContent(codecs, generator),
+	}
+	validationResponse := openAPIResponse{
+		Description: "Validation failed",
+		Content:     errorResponse.Content,
+	}
+	paths := make(map[string]map[string]*openAPIOperation)
+
+	chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		var (
+			operation *openAPIOperation
+			m         meta
+		)
+		switch h := handler.(type) {
+		case *procedureHandler:
+			operation, m = procedureOperation(generator, codecs, h.meta), h.meta
+		case *streamHandler:
+			operation, m = streamOperation(generator, codecs, h.meta, method), h.meta
+		case *clientStreamHandler:
+			operation, m = clientStreamOperation(generator, codecs, h.meta), h.meta
+		default:
+			return nil
+		}
+
+		operation.OperationID = strings.ReplaceAll(strings.Trim(route, "/"), "/", ".")
+		if h, ok := handler.(*streamHandler); ok && method != h.meta.method {
+			// stream is registered with several methods, operation IDs have to be unique
+			operation.OperationID += "." + strings.ToLower(method)
+		}
+		operation.Responses["default"] = errorResponse
+		if isValidated(m.request) {
+			// requests are validated before handler is called
+			operation.Responses["422"] = validationResponse
+		}
+
+		if _, ok := paths[route]; !ok {
+			paths[route] = make(map[string]*openAPIOperation)
+		}
+		paths[route][strings.ToLower(method)] = operation
+
+		return nil
+	})
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		scheme := "http://"
+		if r.TLS != nil {
+			scheme = "https://"
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(openAPIDocument{
+			OpenAPI:    "3.1.0",
+			Info:       info,
+			Servers:    []openAPIServer{{URL: scheme + r.Host}},
+			Paths:      paths,
+			Components: openAPIComponents{Schemas: generator.defs},
+		})
+	}
+}
+
+// procedureOperation describes Procedure handler which accepts and responds with body encoded by any of the codecs.
+// Procedures registered with methods without body accept query parameters instead.
+func procedureOperation(generator *schemaGenerator, codecs []Codec, m meta) *openAPIOperation {
+	operation := &openAPIOperation{
+		Summary: m.name,
+		Responses: map[string]openAPIResponse{
+			"200": {
+				Description: "OK",
+				Content:     content(codecs, generator, m.response),
+			},
+		},
+	}
+
+	if !hasBody(m.method) {
+		operation.Parameters = queryParameters(generator, m)
+		return operation
+	}
+
+	if m.body != nil || m.form != nil {
+		operation.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  make(map[string]openAPIMediaType),
+		}
+		if m.body != nil {
+			operation.RequestBody.Content = content(codecs, generator, m.request)
+		}
+		addFormContent(operation.RequestBody, generator, m)
+	}
+
+	return operation
+}
+
+// addFormContent describes form request body if request has properties with `form` tag.
+// Requests with files can be sent only as multipart/form-data.
+func addFormContent(body *openAPIRequestBody, generator *schemaGenerator, m meta) {
+	if m.form == nil {
+		return
+	}
+
+	schema := formSchema(generator, m.request)
+	body.Content["multipart/form-data"] = openAPIMediaType{Schema: schema}
+	if !hasFiles(m.request) {
+		body.Content["application/x-www-form-urlencoded"] = openAPIMediaType{Schema: schema}
+	}
+}
+
+// clientStreamOperation describes ClientStream handler which accepts newline delimited JSON and responds with
+// body encoded by any of the codecs.
+func clientStreamOperation(generator *schemaGenerator, codecs []Codec, m meta) *openAPIOperation {
+	operation := procedureOperation(generator, codecs, m)
+	operation.RequestBody = &openAPIRequestBody{
+		Description: "Newline delimited JSON records.",
+		Required:    true,
+		Content: map[string]openAPIMediaType{
+			"application/x-ndjson": {Schema: generator.schema(m.request)},
+		},
+	}
+
+	return operation
+}
+
+// streamOperation describes Stream handler which responds with SSE or NDJSON stream.
+// Stream accepts query parameters, unless method carries request in body.
+func streamOperation(generator *schemaGenerator, codecs []Codec, m meta, method string) *openAPIOperation {
+	operation := &openAPIOperation{
+		Summary: m.name,
+		Responses: map[string]openAPIResponse{
+			"200": {
+				Description: "Server-Sent Events stream of " + strings.Join(eventNames(m.response), ", ") + " events. Data of each event is JSON encoded payload named after the event. " +
+					"Clients accepting application/x-ndjson receive every event as a line of JSON object with id, event and data properties.",
+				Content: map[string]openAPIMediaType{
+					"text/event-stream":    {Schema: generator.schema(m.response)},
+					"application/x-ndjson": {Schema: generator.schema(m.response)},
+				},
+			},
+		},
+	}
+
+	if hasBody(method) {
+		operation.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content:  content(codecs, generator, m.request),
+		}
+		addFormContent(operation.RequestBody, generator, m)
+
+		return operation
+	}
+
+	operation.Parameters = queryParameters(generator, m)
+
+	return operation
+}
+
+// queryParameters describes request properties with `query` tag as query parameters.
+func queryParameters(generator *schemaGenerator, m meta) []openAPIParameter {
+	var parameters []openAPIParameter
+	for _, field := range bindingFields(m.request, "query") {
+		schema := querySchema(generator, field.Type)
+		rules := parseRules(field.Tag.Get("validate"))
+		constrain(schema, field.Type, rules)
+
+		parameters = append(parameters, openAPIParameter{
+			Name:     field.key,
+			In:       "query",
+			Required: hasRule(rules, "required"),
+			Schema:   schema,
+		})
+	}
+
+	return parameters
+}
+
+// errorContent describes errors the way DefaultErrorHandler encodes them, validation errors carry fields.
+// Schema is named after Error type.
+func errorContent(codecs []Codec, generator *schemaGenerator) map[string]openAPIMediaType {
+	name := generator.names.of(reflect.TypeOf(Error{}))
+	generator.defs[name] = generator.object(reflect.TypeOf(errorBody{}))
+
+	result := make(map[string]openAPIMediaType, len(codecs))
+	for _, codec := range codecs {
+		if accepts(codec, &errorBody{}) {
+			result[codec.ContentType()] = openAPIMediaType{Schema: &Schema{Ref: generator.refPrefix + name}}
+		}
+	}
+
+	return result
+}
+
+// content describes body of type t in media types of codecs which can encode it.
+func content(codecs []Codec, generator *schemaGenerator, t reflect.Type) map[string]openAPIMediaType {
+	schema, value := generator.schema(t), reflect.New(t).Interface()
+	if isBinary(t) {
+		// binary content is written as it is, its media type is known only at runtime
+		return map[string]openAPIMediaType{"application/octet-stream": {Schema: schema}}
+	}
+
+	result := make(map[string]openAPIMediaType, len(codecs))
+	for _, codec := range codecs {
+		if accepts(codec, value) {
+			result[codec.ContentType()] = openAPIMediaType{Schema: schema}
+		}
+	}
+
+	return result
+}
+
+type openAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []openAPIServer                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components openAPIComponents                       `json:"components"`
+}
+
+type openAPIServer struct {
+	URL string `json:"url"`
+}
+
+type openAPIComponents struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Summary     string                     `json:"summary,omitempty"`
+	Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+type openAPIParameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type openAPIRequestBody struct {
+	Description string                      `json:"description,omitempty"`
+	Required    bool                        `json:"required,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]openAPIMediaType `json:"content,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *Schema `json:"schema"`
+}
@@ -0,0 +1,176 @@
+package ferry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type nestedResponse struct {
+	Payload  testPayload            `json:"payload"`
+	Children []nestedResponse       `json:"children"`
+	Labels   map[string]testPayload `json:"labels"`
+	Internal string                 `json:"-"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	svc := testService{}
+	service := NewRouter()
+	service.Register(
+		Procedure(svc.TestProcedureWithParams),
+		Procedure(func(ctx context.Context, r *empty) (*nestedResponse, error) { return &nestedResponse{}, nil }),
+		Stream(svc.StreamOneEvent),
+		Procedure(svc.FindValue, Method(http.MethodGet)),
+		Procedure(svc.SubmitForm),
+	)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1/TestService", service)
+	handler := OpenAPI(router, OpenAPIInfo{Title: "Test API", Version: "1.0.0"})
+
+	rr := httptest.NewRecorder()
+	handler(rr, httptest.NewRequest(http.MethodGet, "/openapi.json", nil))
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(rr.Body.Bytes(), &document); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("describes document", func(t *testing.T) {
+		if document["openapi"] != "3.1.0" {
+			t.Errorf("unexpected openapi version, got %v", document["openapi"])
+		}
+
+		expected := map[string]interface{}{"title": "Test API", "version": "1.0.0"}
+		if !reflect.DeepEqual(document["info"], expected) {
+			t.Errorf("unexpected info, got %v", document["info"])
+		}
+	})
+
+	t.Run("describes procedure", func(t *testing.T) {
+		operation := lookup(document, "paths", "/api/v1/TestService/TestProcedureWithParams", "post")
+		if operation == nil {
+			t.Fatalf("operation not found, got %v", document["paths"])
+		}
+
+		if ref := lookup(operation, "requestBody", "content", "application/json", "schema", "$ref"); ref != "#/components/schemas/jsonRequest" {
+			t.Errorf("unexpected request schema, got %v", ref)
+		}
+
+		if ref := lookup(operation, "responses", "200", "content", "application/json", "schema", "$ref"); ref != "#/components/schemas/testPayload" {
+			t.Errorf("unexpected response schema, got %v", ref)
+		}
+
+		if ref := lookup(operation, "responses", "default", "content", "application/json", "schema", "$ref"); ref != "#/components/schemas/Error" {
+			t.Errorf("unexpected error schema, got %v", ref)
+		}
+	})
+
+	t.Run("describes errors", func(t *testing.T) {
+		if ref := lookup(document, "components", "schemas", "Error", "properties", "fields", "items", "$ref"); ref != "#/components/schemas/FieldError" {
+			t.Errorf("unexpected error schema, got %v", lookup(document, "components", "schemas", "Error"))
+		}
+
+		validated := lookup(document, "paths", "/api/v1/TestService/SubmitForm", "post", "responses", "422", "content", "application/json", "schema", "$ref")
+		if validated != "#/components/schemas/Error" {
+			t.Errorf("unexpected validation error response, got %v", validated)
+		}
+
+		if response := lookup(document, "paths", "/api/v1/TestService/TestProcedureWithParams", "post", "responses", "422"); response != nil {
+			t.Errorf("unexpected validation error response of request without rules, got %v", response)
+		}
+	})
+
+	t.Run("describes procedure accepting query", func(t *testing.T) {
+		operation := lookup(document, "paths", "/api/v1/TestService/FindValue", "get")
+		if operation == nil {
+			t.Fatalf("operation not found, got %v", document["paths"])
+		}
+
+		expected := []interface{}{
+			map[string]interface{}{"name": "value", "in": "query", "schema": map[string]interface{}{"type": "string"}},
+		}
+		if !reflect.DeepEqual(operation.(map[string]interface{})["parameters"], expected) {
+			t.Errorf("unexpected parameters, got %v", operation.(map[string]interface{})["parameters"])
+		}
+
+		if body := lookup(operation, "requestBody"); body != nil {
+			t.Errorf("unexpected request body, got %v", body)
+		}
+	})
+
+	t.Run("describes stream", func(t *testing.T) {
+		operation := lookup(document, "paths", "/api/v1/TestService/StreamOneEvent", "get")
+		if operation == nil {
+			t.Fatalf("operation not found, got %v", document["paths"])
+		}
+
+		expected := []interface{}{
+			map[string]interface{}{"name": "value", "in": "query", "schema": map[string]interface{}{"type": "string"}},
+		}
+		if !reflect.DeepEqual(operation.(map[string]interface{})["parameters"], expected) {
+			t.Errorf("unexpected parameters, got %v", operation.(map[string]interface{})["parameters"])
+		}
+
+		if ref := lookup(operation, "responses", "200", "content", "text/event-stream", "schema", "$ref"); ref != "#/components/schemas/testPayload" {
+			t.Errorf("unexpected response schema, got %v", ref)
+		}
+
+		if ref := lookup(operation, "responses", "200", "content", "application/x-ndjson", "schema", "$ref"); ref != "#/components/schemas/testPayload" {
+			t.Errorf("unexpected NDJSON response schema, got %v", ref)
+		}
+	})
+
+	t.Run("describes stream accepting JSON body", func(t *testing.T) {
+		operation := lookup(document, "paths", "/api/v1/TestService/StreamOneEvent", "post")
+		if operation == nil {
+			t.Fatalf("operation not found, got %v", document["paths"])
+		}
+
+		if id := lookup(operation, "operationId"); id != "api.v1.TestService.StreamOneEvent.post" {
+			t.Errorf("unexpected operation ID, got %v", id)
+		}
+
+		if parameters := lookup(operation, "parameters"); parameters != nil {
+			t.Errorf("unexpected parameters, got %v", parameters)
+		}
+
+		if ref := lookup(operation, "requestBody", "content", "application/json", "schema", "$ref"); ref != "#/components/schemas/queryRequest" {
+			t.Errorf("unexpected request schema, got %v", ref)
+		}
+	})
+
+	t.Run("describes nested schemas", func(t *testing.T) {
+		expected := map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"payload":  map[string]interface{}{"$ref": "#/components/schemas/testPayload"},
+				"children": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/components/schemas/nestedResponse"}},
+				"labels":   map[string]interface{}{"type": "object", "additionalProperties": map[string]interface{}{"$ref": "#/components/schemas/testPayload"}},
+			},
+			"required": []interface{}{"children", "labels", "payload"},
+		}
+
+		if schema := lookup(document, "components", "schemas", "nestedResponse"); !reflect.DeepEqual(schema, expected) {
+			t.Errorf("unexpected schema, got %v", schema)
+		}
+	})
+}
+
+// lookup returns value from decoded JSON document by given path.
+func lookup(document interface{}, path ...string) interface{} {
+	for _, key := range path {
+		object, ok := document.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		document = object[key]
+	}
+
+	return document
+}
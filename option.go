@@ -1,6 +1,10 @@
 package ferry
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+	"time"
+)
 
 func WithErrorHandler(handler ErrorHandler) func(*mux) {
 	return func(m *mux) {
@@ -19,3 +23,173 @@ func WithMethodNotAllowed(handler http.HandlerFunc) func(*mux) {
 		m.MethodNotAllowed(handler)
 	}
 }
+
+// WithCodecs registers codecs which decode request bodies and encode responses of the Router.
+// Codec replaces already registered codec of the same media type. JSON, CBOR and Protocol Buffers codecs are registered by default,
+// JSON is used when client has no preference.
+func WithCodecs(codecs ...Codec) func(*mux) {
+	return func(m *mux) {
+		registered := append([]Codec(nil), m.codecs...)
+
+		for _, codec := range codecs {
+			replaced := false
+			for i := range registered {
+				if strings.EqualFold(registered[i].ContentType(), codec.ContentType()) {
+					registered[i], replaced = codec, true
+				}
+			}
+
+			if !replaced {
+				registered = append(registered, codec)
+			}
+		}
+
+		m.codecs = registered
+	}
+}
+
+// WithFormLimits sets limits of form and multipart request bodies. By default, bodies are limited to 32 MiB,
+// single field or file to 10 MiB and up to 10 MiB of uploaded files are kept in memory. Zero values keep the defaults.
+func WithFormLimits(limits FormLimits) func(*mux) {
+	return func(m *mux) {
+		if limits.MaxMemory > 0 {
+			m.formLimits.MaxMemory = limits.MaxMemory
+		}
+
+		if limits.MaxPartSize > 0 {
+			m.formLimits.MaxPartSize = limits.MaxPartSize
+		}
+
+		if limits.MaxBodySize > 0 {
+			m.formLimits.MaxBodySize = limits.MaxBodySize
+		}
+	}
+}
+
+// WithInterceptors adds interceptors which wrap every Procedure registered in the Router.
+// Interceptors are called in the given order.
+func WithInterceptors(interceptors ...Interceptor) func(*mux) {
+	return func(m *mux) {
+		m.interceptors = append(m.interceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors adds interceptors which are called for every Stream registered in the Router.
+// Interceptors are called in the given order.
+func WithStreamInterceptors(interceptors ...StreamInterceptor) func(*mux) {
+	return func(m *mux) {
+		m.streamInterceptors = append(m.streamInterceptors, interceptors...)
+	}
+}
+
+// WithHeartbeat sets how often and in which style heartbeats are sent by streams when there is no activity.
+// By default, "keep-alive" event is sent every 5 seconds. Zero interval keeps the default.
+func WithHeartbeat(interval time.Duration, style HeartbeatStyle) func(*mux) {
+	return func(m *mux) {
+		m.stream = m.stream.merge(streamConfig{heartbeat: interval, heartbeatStyle: style})
+	}
+}
+
+// WithRetry sets reconnection time which is sent to stream clients at connection start.
+func WithRetry(retry time.Duration) func(*mux) {
+	return func(m *mux) {
+		m.stream.retry = retry
+	}
+}
+
+// WithMaxLifetime sets maximum duration of streams. When it is reached, stream is ended
+// and client is expected to reconnect, possibly to another instance.
+func WithMaxLifetime(lifetime time.Duration) func(*mux) {
+	return func(m *mux) {
+		m.stream.maxLifetime = lifetime
+	}
+}
+
+// WithReplayBuffer sets buffer which stores stream events, so they are replayed to clients reconnecting
+// with Last-Event-ID header before live events are delivered.
+func WithReplayBuffer(buffer ReplayBuffer) func(*mux) {
+	return func(m *mux) {
+		m.stream.replay = buffer
+	}
+}
+
+// WithReplayKey sets function which identifies streams stored in replay buffer.
+// By default, streams are identified by request path and query, so events are shared by all clients
+// requesting the same path and query. Streams which send events of particular user have to be keyed by user.
+func WithReplayKey(key ReplayKeyFunc) func(*mux) {
+	return func(m *mux) {
+		m.stream.replayKey = key
+	}
+}
+
+// WithLeakPolicy sets policy which is called when Stream function does not close its channel
+// within timeout after stream has finished. By default, leaks are logged after 5 seconds.
+func WithLeakPolicy(timeout time.Duration, policy LeakPolicy) func(*mux) {
+	return func(m *mux) {
+		m.leakTimeout = timeout
+		m.leakPolicy = policy
+	}
+}
+
+// Name sets handler name. By default, name is reflected from function name.
+// Handler path defaults to "/" + name.
+func Name(name string) func(*meta) {
+	return func(m *meta) {
+		m.name = name
+	}
+}
+
+// Path sets handler path relative to the Router.
+func Path(path string) func(*meta) {
+	return func(m *meta) {
+		m.path = "/" + strings.TrimPrefix(path, "/")
+	}
+}
+
+// Method sets HTTP method handler is registered with.
+// By default, Procedure is registered with POST and Stream with both GET and POST methods.
+// Requests of POST, PUT and PATCH methods are decoded from body, requests of other methods are bound from query.
+func Method(method string) func(*meta) {
+	return func(m *meta) {
+		m.method = method
+	}
+}
+
+// Heartbeat sets how often and in which style Stream sends heartbeats when there is no activity.
+// It overrides router-wide WithHeartbeat option. Zero interval keeps the router-wide interval.
+func Heartbeat(interval time.Duration, style HeartbeatStyle) func(*meta) {
+	return func(m *meta) {
+		m.stream.heartbeat = interval
+		m.stream.heartbeatStyle = style
+	}
+}
+
+// Retry sets reconnection time which is sent to Stream clients at connection start.
+// It overrides router-wide WithRetry option.
+func Retry(retry time.Duration) func(*meta) {
+	return func(m *meta) {
+		m.stream.retry = retry
+	}
+}
+
+// MaxLifetime sets maximum duration of Stream. It overrides router-wide WithMaxLifetime option.
+func MaxLifetime(lifetime time.Duration) func(*meta) {
+	return func(m *meta) {
+		m.stream.maxLifetime = lifetime
+	}
+}
+
+// Replay sets buffer which stores Stream events, so they are replayed to reconnecting clients.
+// It overrides router-wide WithReplayBuffer option.
+func Replay(buffer ReplayBuffer) func(*meta) {
+	return func(m *meta) {
+		m.stream.replay = buffer
+	}
+}
+
+// ReplayKey sets function which identifies Stream in replay buffer. It overrides router-wide WithReplayKey option.
+func ReplayKey(key ReplayKeyFunc) func(*meta) {
+	return func(m *meta) {
+		m.stream.replayKey = key
+	}
+}
@@ -3,33 +3,51 @@ package ferry
 import (
 	"context"
 	"net/http"
+	"reflect"
 )
 
 // Procedure will return Handler which can be used to register remote procedure in Router.
-// This function call will panic if procedure function does not have receiver or Request structure is unparsable.
-func Procedure[Req any, Res any](fn func(ctx context.Context, r *Req) (*Res, error)) Handler {
-	mt, err := buildMeta(fn, new(Req))
-	if err != nil {
-		panic(err)
-	}
+// Options (Name, Path, Method) can be used to decouple endpoint from Go function name.
+// Request is decoded from body, unless procedure is registered with method which has no body (e.g. GET or DELETE),
+// in which case request properties with `query` tag are bound from query.
+// Handler is named after the function, anonymous functions get generated names, so they should be named with Name option.
+// This function call will panic if Request structure is unparsable.
+func Procedure[Req any, Res any](fn func(ctx context.Context, r *Req) (*Res, error), options ...func(*meta)) Handler {
+	mt := handlerMeta(fn, reflect.TypeOf(new(Req)).Elem(), reflect.TypeOf(new(Res)).Elem(), options)
+
+	return newProcedure(mt, func(ctx context.Context, r interface{}) (interface{}, error) {
+		return fn(ctx, r.(*Req))
+	})
+}
 
-	decodeFn := decodeJSON[Req]
-	if len(mt.body) == 0 {
+// newProcedure creates procedure handler. Call receives pointer to decoded request of mt.request type.
+func newProcedure(mt meta, call func(ctx context.Context, r interface{}) (interface{}, error)) *procedureHandler {
+	mt.setDefaults(http.MethodPost)
+
+	decodeFn := decodeBody
+	switch {
+	case !hasBody(mt.method) && mt.query != nil:
+		// requests of methods without body, e.g. GET or DELETE, are bound from query
+		decodeFn = decodeQuery
+	case !hasBody(mt.method) || (mt.body == nil && mt.form == nil):
 		// skip decoding if there are no parameters.
-		decodeFn = func(r *http.Request, v *Req) error { return nil }
+		decodeFn = func(r *http.Request, v interface{}) error { return nil }
 	}
 
 	return &procedureHandler{
 		meta: mt,
 		builder: func(m *mux) http.HandlerFunc {
+			call := intercept(m.interceptors, procedureInfo(mt), call)
+
 			return func(w http.ResponseWriter, r *http.Request) {
-				var requestValue Req
-				if err := decodeFn(r, &requestValue); err != nil {
+				requestValue := reflect.New(mt.request).Interface()
+				if err := decodeFn(r, requestValue); err != nil {
 					m.errHandler(w, r, err)
 					return
 				}
+				defer closeRequestFiles(requestValue)
 
-				response, err := fn(createContext(w, r), &requestValue)
+				response, err := call(createContext(w, r), requestValue)
 				if err != nil {
 					m.errHandler(w, r, err)
 					return
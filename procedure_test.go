@@ -18,6 +18,10 @@ func (t testService) TestProcedureWithParams(ctx context.Context, r *jsonRequest
 	return &testPayload{Value: r.Value}, nil
 }
 
+func (t testService) FindValue(ctx context.Context, r *queryRequest) (*testPayload, error) {
+	return &testPayload{Value: r.Value}, nil
+}
+
 func TestProcedure(t *testing.T) {
 	t.Run("returns response without request params", func(t *testing.T) {
 		t.Parallel()
@@ -90,4 +94,17 @@ func TestProcedure(t *testing.T) {
 			t.Errorf("unexpected response code, got %d", rr.Code)
 		}
 	})
+
+	t.Run("binds query of procedure registered with GET method", func(t *testing.T) {
+		t.Parallel()
+		router := NewRouter()
+		svc := testService{}
+		router.Register(Procedure(svc.FindValue, Method(http.MethodGet)))
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/FindValue?value=test_data", nil))
+
+		if content := rr.Body.String(); rr.Code != http.StatusOK || content != `{"value":"test_data"}` {
+			t.Errorf("unexpected response, got %d %s", rr.Code, content)
+		}
+	})
 }
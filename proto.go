@@ -0,0 +1,320 @@
+package ferry
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Proto walks chi.Router routing tree and creates http.HandlerFunc
+// that will return Protocol Buffers (proto3) definition of messages and services of ferry endpoints.
+// Handlers are grouped to services by parent segment of their path. Fields of generated
+// Protocol Buffers messages keep their numbers, other fields are numbered in order of declaration.
+// Only procedures which request and response implement proto.Message accept and produce application/protobuf.
+// Messages of other types describe JSON wire format only: their fields keep JSON names with json_name option,
+// so stubs generated from the definition can call such endpoints with Protocol Buffers JSON mapping.
+// Rpcs of such endpoints are marked as "JSON only".
+func Proto(router chi.Router, pkg string) http.HandlerFunc {
+	generator := newProtoGenerator()
+	services := make(map[string][]string)
+
+	chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		var (
+			m    meta
+			kind string
+		)
+		switch h := handler.(type) {
+		case *procedureHandler:
+			m, kind = h.meta, "(%s) returns (%s)"
+		case *streamHandler:
+			m, kind = h.meta, "(%s) returns (stream %s)"
+		case *bidiHandler:
+			m, kind = h.meta, "(stream %s) returns (stream %s)"
+		case *clientStreamHandler:
+			m, kind = h.meta, "(stream %s) returns (%s)"
+		default:
+			return nil
+		}
+
+		if method != m.method {
+			// stream registered with additional methods is described once
+			return nil
+		}
+
+		segments := strings.Split(strings.Trim(route, "/"), "/")
+		rpc := protoIdentifier(segments[len(segments)-1])
+		service := "Service"
+		if len(segments) > 1 {
+			service = protoIdentifier(segments[len(segments)-2])
+		}
+
+		request := generator.message(m.request, rpc+"Request")
+		response := generator.message(m.response, rpc+"Response")
+
+		comment := method + " " + route
+		if _, ok := handler.(*procedureHandler); !ok || !isProtoMessage(m.request) || !isProtoMessage(m.response) {
+			comment += " (JSON only)"
+		}
+
+		services[service] = append(services[service], fmt.Sprintf("  // %s\n  rpc %s"+kind+";\n",
+			comment, rpc, request, response))
+
+		return nil
+	})
+
+	var source strings.Builder
+	source.WriteString("// Code generated by ferry. DO NOT EDIT.\n\nsyntax = \"proto3\";\n\n")
+	if pkg != "" {
+		fmt.Fprintf(&source, "package %s;\n\n", pkg)
+	}
+	source.WriteString(generator.imports())
+
+	names := make([]string, 0, len(services))
+	for name := range services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(&source, "service %s {\n%s}\n\n", name, strings.Join(services[name], ""))
+	}
+	source.WriteString(generator.declarations())
+
+	definition := strings.TrimSuffix(source.String(), "\n")
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, definition)
+	}
+}
+
+// protoGenerator maps Go types to Protocol Buffers types.
+// Struct types are declared as messages, well-known types are imported when they are used.
+type protoGenerator struct {
+	messages map[string]string
+	imported map[string]bool
+	names    *typeNames
+}
+
+func newProtoGenerator() *protoGenerator {
+	return &protoGenerator{messages: make(map[string]string), imported: make(map[string]bool), names: newTypeNames()}
+}
+
+// message returns name of the message describing type t. Types which are not named structs
+// are declared as message with given name, non-struct types are wrapped to its "value" field.
+func (g *protoGenerator) message(t reflect.Type, name string) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct && t != timeType && !isBinary(t) {
+		typ, _ := g.typeOf(t, name)
+		return typ
+	}
+
+	typ, label := g.typeOf(t, name+"Value")
+	g.messages[name] = fmt.Sprintf("{\n  %s%s value = 1;\n}", label, typ)
+
+	return name
+}
+
+// typeOf returns Protocol Buffers type of the given type and label of the field of such type.
+// Name is used to declare message of anonymous struct.
+func (g *protoGenerator) typeOf(t reflect.Type, name string) (string, string) {
+	if t == timeType {
+		return g.wellKnown("Timestamp", "google/protobuf/timestamp.proto"), ""
+	}
+	if isBinary(t) {
+		return "bytes", ""
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		typ, label := g.typeOf(t.Elem(), name)
+		if label == "" && t.Elem().Kind() != reflect.Struct {
+			// pointers to scalars keep track of presence
+			label = "optional "
+		}
+		return typ, label
+	case reflect.String:
+		return "string", ""
+	case reflect.Bool:
+		return "bool", ""
+	case reflect.Int, reflect.Int64:
+		return "int64", ""
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return "int32", ""
+	case reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return "uint64", ""
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return "uint32", ""
+	case reflect.Float32:
+		return "float", ""
+	case reflect.Float64:
+		return "double", ""
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return "bytes", ""
+		}
+		typ, label := g.typeOf(t.Elem(), name)
+		if label == "repeated " || strings.HasPrefix(typ, "map<") {
+			// repeated fields can not be nested
+			return g.wellKnown("ListValue", "google/protobuf/struct.proto"), "repeated "
+		}
+		return typ, "repeated "
+	case reflect.Map:
+		key, _ := g.typeOf(t.Key(), name)
+		if !isProtoMapKey(key) {
+			return g.wellKnown("Struct", "google/protobuf/struct.proto"), ""
+		}
+		value, label := g.typeOf(t.Elem(), name)
+		if label == "repeated " || strings.HasPrefix(value, "map<") {
+			// map values can not be repeated
+			value = g.wellKnown("Value", "google/protobuf/struct.proto")
+		}
+		return fmt.Sprintf("map<%s, %s>", key, value), ""
+	case reflect.Struct:
+		if t.Name() != "" {
+			name = g.names.of(t)
+		}
+		if _, ok := g.messages[name]; !ok {
+			// placeholder prevents infinite recursion on self-referencing types
+			g.messages[name] = ""
+			g.messages[name] = g.fields(t, name)
+		}
+		return name, ""
+	case reflect.Interface:
+		return g.wellKnown("Any", "google/protobuf/any.proto"), ""
+	default:
+		return g.wellKnown("Value", "google/protobuf/struct.proto"), ""
+	}
+}
+
+// fields returns body of message declaring fields of struct type t.
+func (g *protoGenerator) fields(t reflect.Type, name string) string {
+	fields := jsonFields(t)
+	if len(fields) == 0 {
+		return "{}"
+	}
+
+	var out strings.Builder
+	out.WriteString("{\n")
+	for i, field := range fields {
+		number, fieldName := i+1, protoIdentifier(field.Name)
+		// fields of generated messages keep their numbers and names
+		for _, option := range strings.Split(field.Protobuf, ",") {
+			if n, err := strconv.Atoi(option); err == nil {
+				number = n
+			}
+			if strings.HasPrefix(option, "name=") {
+				fieldName = strings.TrimPrefix(option, "name=")
+			}
+		}
+
+		option := ""
+		if field.Protobuf == "" && protoJSONName(fieldName) != field.Name {
+			// fields of plain structs are encoded by their JSON names
+			option = fmt.Sprintf(" [json_name = %q]", field.Name)
+		}
+
+		typ, label := g.typeOf(field.Type, name+protoIdentifier(strings.ToUpper(field.Name[:1])+field.Name[1:]))
+		fmt.Fprintf(&out, "  %s%s %s = %d%s;\n", label, typ, fieldName, number, option)
+	}
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// wellKnown returns name of well-known type and imports its definition.
+func (g *protoGenerator) wellKnown(name, path string) string {
+	g.imported[path] = true
+	return "google.protobuf." + name
+}
+
+// imports returns import statements of used well-known types sorted by path.
+func (g *protoGenerator) imports() string {
+	paths := make([]string, 0, len(g.imported))
+	for path := range g.imported {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var out strings.Builder
+	for _, path := range paths {
+		fmt.Fprintf(&out, "import %q;\n", path)
+	}
+	if out.Len() > 0 {
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
+
+// declarations returns messages of all struct types sorted by name.
+func (g *protoGenerator) declarations() string {
+	names := make([]string, 0, len(g.messages))
+	for name := range g.messages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&out, "message %s %s\n\n", name, g.messages[name])
+	}
+
+	return out.String()
+}
+
+// isProtoMessage reports whether type t is encoded by ProtobufCodec.
+func isProtoMessage(t reflect.Type) bool {
+	return accepts(ProtobufCodec, reflect.New(t).Interface())
+}
+
+// protoJSONName returns JSON name Protocol Buffers compiler gives to field with given name:
+// underscores are removed and letters following them are capitalized.
+func protoJSONName(name string) string {
+	var out strings.Builder
+	upper := false
+	for _, r := range name {
+		switch {
+		case r == '_':
+			upper = true
+		case upper:
+			out.WriteRune(unicode.ToUpper(r))
+			upper = false
+		default:
+			out.WriteRune(r)
+		}
+	}
+
+	return out.String()
+}
+
+// isProtoMapKey reports whether type can be used as key of map field.
+func isProtoMapKey(typ string) bool {
+	switch typ {
+	case "string", "bool", "int32", "int64", "uint32", "uint64":
+		return true
+	default:
+		return false
+	}
+}
+
+// protoIdentifier replaces characters which are not allowed in identifiers with underscores.
+func protoIdentifier(name string) string {
+	identifier := []rune(name)
+	for i, r := range identifier {
+		if !(unicode.IsLetter(r) && r < unicode.MaxASCII || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			identifier[i] = '_'
+		}
+	}
+
+	return string(identifier)
+}
@@ -0,0 +1,136 @@
+package ferry
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type protoRequest struct {
+	Since  time.Time         `json:"since"`
+	Limit  *int32            `json:"limit"`
+	Tags   map[string]string `json:"tags"`
+	Matrix [][]float64       `json:"matrix"`
+	Filter struct {
+		Name string `json:"name"`
+	} `json:"filter"`
+	PageSize int32 `json:"page_size"`
+}
+
+func (t testService) TestProto(ctx context.Context, r *protoRequest) (*[]testPayload, error) {
+	return &[]testPayload{}, nil
+}
+
+func (t testService) Uppercase(ctx context.Context, r *wrapperspb.StringValue) (*wrapperspb.StringValue, error) {
+	return wrapperspb.String(strings.ToUpper(r.GetValue())), nil
+}
+
+func TestProto(t *testing.T) {
+	svc := testService{}
+	service := NewRouter()
+	service.Register(
+		Procedure(svc.TestProto),
+		Procedure(svc.Uppercase),
+		Stream(svc.StreamOneEvent),
+	)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1/TestService", service)
+
+	rr := httptest.NewRecorder()
+	Proto(router, "api.v1")(rr, httptest.NewRequest(http.MethodGet, "/ferry.proto", nil))
+	definition := rr.Body.String()
+
+	expected := []string{
+		"syntax = \"proto3\";\n\npackage api.v1;\n\nimport \"google/protobuf/struct.proto\";\nimport \"google/protobuf/timestamp.proto\";\n",
+		"service TestService {\n",
+		"  // GET /api/v1/TestService/StreamOneEvent (JSON only)\n  rpc StreamOneEvent(queryRequest) returns (stream testPayload);\n",
+		"  // POST /api/v1/TestService/TestProto (JSON only)\n  rpc TestProto(protoRequest) returns (TestProtoResponse);\n",
+		"  // POST /api/v1/TestService/Uppercase\n  rpc Uppercase(StringValue) returns (StringValue);\n",
+		"message StringValue {\n  string value = 1;\n}",
+		"message TestProtoResponse {\n  repeated testPayload value = 1;\n}",
+		"message protoRequest {\n  google.protobuf.Timestamp since = 1;\n  optional int32 limit = 2;\n  map<string, string> tags = 3;\n  repeated google.protobuf.ListValue matrix = 4;\n  protoRequestFilter filter = 5;\n  int32 page_size = 6 [json_name = \"page_size\"];\n}",
+		"message protoRequestFilter {\n  string name = 1;\n}",
+		"message queryRequest {\n  string Value = 1;\n}",
+	}
+
+	for _, fragment := range expected {
+		if !strings.Contains(definition, fragment) {
+			t.Errorf("expected definition to contain:\n%s\ngot:\n%s", fragment, definition)
+		}
+	}
+
+	if strings.Contains(definition, "StreamOneEvent.post") || strings.Count(definition, "rpc StreamOneEvent") != 1 {
+		t.Errorf("expected stream to be described once, got:\n%s", definition)
+	}
+}
+
+func TestProtobufCodec(t *testing.T) {
+	router := NewRouter()
+	svc := testService{}
+	router.Register(Procedure(svc.Uppercase), Procedure(svc.TestProcedureWithParams))
+
+	t.Run("decodes and encodes protobuf", func(t *testing.T) {
+		body, err := proto.Marshal(wrapperspb.String("test"))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Uppercase", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/protobuf")
+		r.Header.Set("Accept", "application/protobuf")
+		router.ServeHTTP(rr, r)
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "application/protobuf" {
+			t.Errorf("unexpected content type, got %q", contentType)
+		}
+
+		response := new(wrapperspb.StringValue)
+		if err := proto.Unmarshal(rr.Body.Bytes(), response); err != nil || response.GetValue() != "TEST" {
+			t.Errorf("unexpected response, got %v, %v", response, err)
+		}
+	})
+
+	t.Run("falls back to JSON for other types", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", strings.NewReader(`{"value":"test"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/protobuf")
+		router.ServeHTTP(rr, r)
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "application/json; charset=utf-8" {
+			t.Errorf("unexpected content type, got %q", contentType)
+		}
+	})
+
+	t.Run("rejects protobuf body of other types", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", strings.NewReader(""))
+		r.Header.Set("Content-Type", "application/protobuf")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("expected status code %d, got %d", http.StatusUnsupportedMediaType, rr.Code)
+		}
+	})
+
+	t.Run("rejects malformed protobuf", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/Uppercase", strings.NewReader("\xff"))
+		r.Header.Set("Content-Type", "application/protobuf")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package ferry
+
+import (
+	"container/list"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// ReplayBuffer stores Stream events, so they can be replayed to clients which reconnect with Last-Event-ID header.
+// Events are stored per stream key, see ReplayKey option. Only events with ID are stored.
+// Stored events are values of Event type.
+type ReplayBuffer interface {
+	// Store saves event of the stream identified by key.
+	Store(key string, event any)
+	// Since returns events of the stream identified by key which were stored after event with given ID.
+	// It returns nil if event with given ID is not in the buffer.
+	Since(key, lastEventID string) []any
+}
+
+// NewReplayBuffer creates in-memory ReplayBuffer which keeps last size events of at most streams streams.
+// Clients control stream keys with query parameters, so when the limit is reached,
+// events of the least recently used stream are dropped.
+func NewReplayBuffer(size, streams int) ReplayBuffer {
+	if size <= 0 {
+		panic("replay buffer size must be positive")
+	}
+	if streams <= 0 {
+		panic("replay buffer streams must be positive")
+	}
+
+	return &ringBuffer{size: size, streams: streams, rings: make(map[string]*ring), recent: list.New()}
+}
+
+// ringBuffer is the in-memory implementation of ReplayBuffer.
+// Recent lists keys of streams from the most to the least recently used.
+type ringBuffer struct {
+	mu      sync.Mutex
+	size    int
+	streams int
+	rings   map[string]*ring
+	recent  *list.List
+}
+
+// ring keeps events in circular buffer, start is the index of the oldest event.
+type ring struct {
+	ids    []string
+	events []any
+	start  int
+	usage  *list.Element
+}
+
+func (b *ringBuffer) Store(key string, event any) {
+	e, ok := event.(streamEvent)
+	if !ok {
+		return
+	}
+
+	id, _, _ := e.event()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r, ok := b.rings[key]
+	if !ok {
+		if len(b.rings) == b.streams {
+			oldest := b.recent.Back()
+			delete(b.rings, b.recent.Remove(oldest).(string))
+		}
+
+		r = &ring{usage: b.recent.PushFront(key)}
+		b.rings[key] = r
+	}
+	b.recent.MoveToFront(r.usage)
+
+	// the same event is stored by every client subscribed to the stream
+	for i := range r.ids {
+		if r.ids[i] == id {
+			return
+		}
+	}
+
+	if len(r.events) < b.size {
+		r.ids = append(r.ids, id)
+		r.events = append(r.events, event)
+		return
+	}
+
+	r.ids[r.start] = id
+	r.events[r.start] = event
+	r.start = (r.start + 1) % b.size
+}
+
+func (b *ringBuffer) Since(key, lastEventID string) []any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r, ok := b.rings[key]
+	if !ok {
+		return nil
+	}
+	b.recent.MoveToFront(r.usage)
+
+	for i := range r.ids {
+		index := (r.start + i) % len(r.ids)
+		if r.ids[index] != lastEventID {
+			continue
+		}
+
+		events := make([]any, 0, len(r.ids)-i-1)
+		for j := i + 1; j < len(r.ids); j++ {
+			events = append(events, r.events[(r.start+j)%len(r.ids)])
+		}
+
+		return events
+	}
+
+	return nil
+}
+
+// ReplayKeyFunc returns key of the stream which events are stored in ReplayBuffer.
+// It receives HTTP request and pointer to decoded Stream request.
+type ReplayKeyFunc func(r *http.Request, request any) string
+
+// replayKey identifies stream by request path and sorted query. It is the default ReplayKeyFunc,
+// so clients requesting the same path and query share the stream regardless of their credentials or request body.
+func replayKey(r *http.Request, _ any) string {
+	key := r.URL.Path
+	if query := r.URL.Query().Encode(); query != "" {
+		key += "?" + query
+	}
+
+	return key
+}
+
+// bindLastEventID sets Last-Event-ID header value to string fields of request tagged with `sse:"last-event-id"`.
+func bindLastEventID(r *http.Request, v interface{}) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		return
+	}
+
+	if indirectType(reflect.TypeOf(v)).Kind() != reflect.Struct {
+		return
+	}
+
+	value := reflect.ValueOf(v).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.Tag.Get("sse") == "last-event-id" && field.Type.Kind() == reflect.String && field.IsExported() {
+			value.Field(i).SetString(lastEventID)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package ferry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type resumeRequest struct {
+	LastEventID string `sse:"last-event-id"`
+	Value       string `query:"value"`
+}
+
+func (s testService) ResumableStream(ctx context.Context, r *resumeRequest) (<-chan Event[testPayload], error) {
+	c := make(chan Event[testPayload])
+
+	go func() {
+		defer close(c)
+
+		if r.LastEventID != LastEventID(ctx) {
+			panic("last event id is not bound")
+		}
+
+		// resumed streams rely on replay buffer
+		if r.LastEventID != "" {
+			return
+		}
+
+		for _, id := range []string{"1", "2", "3"} {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- Event[testPayload]{ID: id, Payload: &testPayload{Value: r.Value + id}}:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+func TestReplayBuffer(t *testing.T) {
+	event := func(id string) any { return Event[testPayload]{ID: id, Payload: &testPayload{Value: id}} }
+	ids := func(events []any) []string {
+		result := make([]string, 0, len(events))
+		for _, e := range events {
+			id, _, _ := e.(streamEvent).event()
+			result = append(result, id)
+		}
+		return result
+	}
+
+	buffer := NewReplayBuffer(3, 2)
+	for _, id := range []string{"1", "2", "3", "3", "4"} {
+		buffer.Store("stream", event(id))
+	}
+
+	t.Run("returns events after last event id", func(t *testing.T) {
+		if result := ids(buffer.Since("stream", "2")); !reflect.DeepEqual(result, []string{"3", "4"}) {
+			t.Errorf("unexpected events, got %v", result)
+		}
+	})
+
+	t.Run("returns no events after the latest one", func(t *testing.T) {
+		if result := buffer.Since("stream", "4"); len(result) != 0 {
+			t.Errorf("unexpected events, got %v", result)
+		}
+	})
+
+	t.Run("returns nil for evicted event", func(t *testing.T) {
+		if result := buffer.Since("stream", "1"); result != nil {
+			t.Errorf("unexpected events, got %v", result)
+		}
+	})
+
+	t.Run("returns nil for unknown stream", func(t *testing.T) {
+		if result := buffer.Since("unknown", "2"); result != nil {
+			t.Errorf("unexpected events, got %v", result)
+		}
+	})
+
+	t.Run("drops least recently used stream", func(t *testing.T) {
+		buffer := NewReplayBuffer(3, 2)
+		buffer.Store("first", event("1"))
+		buffer.Store("first", event("2"))
+		buffer.Store("second", event("1"))
+		buffer.Store("second", event("2"))
+
+		// reading the first stream makes the second one least recently used
+		buffer.Since("first", "1")
+		buffer.Store("third", event("1"))
+
+		if result := ids(buffer.Since("first", "1")); !reflect.DeepEqual(result, []string{"2"}) {
+			t.Errorf("unexpected events of first stream, got %v", result)
+		}
+		if result := buffer.Since("second", "1"); result != nil {
+			t.Errorf("expected second stream to be dropped, got %v", result)
+		}
+	})
+
+}
+
+func TestReplay(t *testing.T) {
+	svc := testService{}
+	router := NewRouter(WithReplayBuffer(NewReplayBuffer(10, 100)))
+	router.Register(Stream(svc.ResumableStream))
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ResumableStream?value=test", nil))
+
+	r := httptest.NewRequest(http.MethodGet, "/ResumableStream?value=test", nil)
+	r.Header.Set("Last-Event-ID", "1")
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, r)
+
+	expected := "event: keep-alive\n\n" +
+		"id: 2\nevent: testPayload\ndata: {\"value\":\"test2\"}\n\n" +
+		"id: 3\nevent: testPayload\ndata: {\"value\":\"test3\"}\n\n"
+
+	if body := rr.Body.String(); body != expected {
+		t.Errorf("unexpected response, got %q", body)
+	}
+}
+
+func TestReplayKey(t *testing.T) {
+	// userStream sends events of the user identified by Authorization header
+	userStream := func(options ...func(*meta)) Handler {
+		return Stream(func(ctx context.Context, r *resumeRequest) (<-chan Event[testPayload], error) {
+			user := ctx.Value(Request).(*http.Request).Header.Get("Authorization")
+			c := make(chan Event[testPayload], 2)
+			if r.LastEventID == "" {
+				c <- Event[testPayload]{ID: "1", Payload: &testPayload{Value: user + "1"}}
+				c <- Event[testPayload]{ID: "2", Payload: &testPayload{Value: user + "2"}}
+			}
+			close(c)
+
+			return c, nil
+		}, append(options, Name("UserStream"))...)
+	}
+
+	subscribe := func(router Router, user, lastEventID string) string {
+		r := httptest.NewRequest(http.MethodGet, "/UserStream", nil)
+		r.Header.Set("Authorization", user)
+		if lastEventID != "" {
+			r.Header.Set("Last-Event-ID", lastEventID)
+		}
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, r)
+
+		return rr.Body.String()
+	}
+
+	t.Run("shares stream by path and query", func(t *testing.T) {
+		router := NewRouter(WithReplayBuffer(NewReplayBuffer(10, 100)))
+		router.Register(userStream())
+
+		subscribe(router, "alice", "")
+		expected := "event: keep-alive\n\nid: 2\nevent: testPayload\ndata: {\"value\":\"alice2\"}\n\n"
+		if body := subscribe(router, "bob", "1"); body != expected {
+			t.Errorf("unexpected response, got %q", body)
+		}
+	})
+
+	t.Run("separates streams by key", func(t *testing.T) {
+		router := NewRouter(WithReplayBuffer(NewReplayBuffer(10, 100)))
+		router.Register(userStream(ReplayKey(func(r *http.Request, request any) string {
+			return r.Header.Get("Authorization") + ":" + r.URL.Path
+		})))
+
+		subscribe(router, "alice", "")
+		subscribe(router, "bob", "")
+
+		expected := "event: keep-alive\n\nid: 2\nevent: testPayload\ndata: {\"value\":\"bob2\"}\n\n"
+		if body := subscribe(router, "bob", "1"); body != expected {
+			t.Errorf("unexpected response, got %q", body)
+		}
+	})
+
+	t.Run("ignores last event id of non-struct request", func(t *testing.T) {
+		router := NewRouter(WithReplayBuffer(NewReplayBuffer(10, 100)))
+		router.Register(Stream(func(ctx context.Context, r *string) (<-chan Event[testPayload], error) {
+			c := make(chan Event[testPayload])
+			close(c)
+			return c, nil
+		}, Name("ScalarStream"), Method(http.MethodPost)))
+
+		r := httptest.NewRequest(http.MethodPost, "/ScalarStream", strings.NewReader(`"value"`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Last-Event-ID", "1")
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+}
@@ -1,7 +1,10 @@
 package ferry
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -10,7 +13,12 @@ import (
 // This router is intended to be mounted on regular chi Router.
 type Router interface {
 	// Register registers Procedure or Stream Handler.
-	Register(...Handler)
+	// It returns error if handler with the same name or route is already registered.
+	Register(...Handler) error
+	// Shutdown signals open streams of this Router to finish and waits until they do or context is done.
+	// Streams opened after Shutdown is called are rejected with 503 Service Unavailable.
+	// It should be called before http.Server.Shutdown, which does not cancel contexts of active requests.
+	Shutdown(ctx context.Context) error
 
 	chi.Router
 }
@@ -21,14 +29,23 @@ func NewRouter(options ...func(m *mux)) Router {
 
 	m := &mux{
 		errHandler: DefaultErrorHandler,
-		Router:     router,
+		codecs:     defaultCodecs,
+		formLimits: defaultFormLimits,
+		stream: streamConfig{
+			heartbeat:      5 * time.Second,
+			heartbeatStyle: HeartbeatEvent,
+			replayKey:      replayKey,
+		},
+		streams:     newSupervisor(),
+		leakPolicy:  LogLeaks,
+		leakTimeout: 5 * time.Second,
+		names:       make(map[string]bool),
+		routes:      make(map[string]bool),
+		Router:      router,
 	}
 
 	notFound := func(w http.ResponseWriter, r *http.Request) {
-		if err := Encode(w, r, http.StatusNotFound, ClientError{
-			Code:    http.StatusNotFound,
-			Message: "not found",
-		}); err != nil {
+		if err := Encode(w, r, http.StatusNotFound, newErrorBody(NotFound("not found"))); err != nil {
 			m.errHandler(w, r, err)
 		}
 	}
@@ -40,28 +57,73 @@ func NewRouter(options ...func(m *mux)) Router {
 		options[i](m)
 	}
 
+	// store Router in request context, so Encode and decoders can use its configuration
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), muxKey, m)))
+		})
+	})
+
 	return m
 }
 
 // mux is the implementation of Router interface.
 type mux struct {
-	errHandler ErrorHandler
+	errHandler         ErrorHandler
+	codecs             []Codec
+	formLimits         FormLimits
+	interceptors       []Interceptor
+	streamInterceptors []StreamInterceptor
+	stream             streamConfig
+	streams            *supervisor
+	leakPolicy         LeakPolicy
+	leakTimeout        time.Duration
+	// names and routes of registered handlers.
+	names  map[string]bool
+	routes map[string]bool
 
 	chi.Router
 }
 
+// Shutdown finishes open streams of the Router and rejects new ones. Other routers are not affected.
+func (m *mux) Shutdown(ctx context.Context) error {
+	return m.streams.shutdown(ctx)
+}
+
 // Register registers Procedure or Stream handlers to the Router.
-func (m *mux) Register(handlers ...Handler) {
+// Handlers are registered only if none of them clash with each other or with already registered handlers.
+func (m *mux) Register(handlers ...Handler) error {
+	names := make(map[string]bool)
+	routes := make(map[string]bool)
+
 	for _, handler := range handlers {
-		handler.build(m)
+		mt := handler.describe()
+		if mt.name == "" {
+			return fmt.Errorf("can not register handler for %s %s: name is empty", mt.method, mt.path)
+		}
+		if m.names[mt.name] || names[mt.name] {
+			return fmt.Errorf("can not register %q: handler with the same name is already registered", mt.name)
+		}
+		names[mt.name] = true
 
-		switch h := handler.(type) {
-		case *procedureHandler:
-			m.Method(http.MethodPost, "/"+h.meta.name, handler)
-		case *streamHandler:
-			m.Method(http.MethodGet, "/"+h.meta.name, handler)
-		default:
-			continue
+		for _, method := range mt.methods() {
+			route := method + " " + mt.path
+			if m.routes[route] || routes[route] {
+				return fmt.Errorf("can not register %q: route %s is already registered", mt.name, route)
+			}
+			routes[route] = true
 		}
 	}
+
+	for _, handler := range handlers {
+		mt := handler.describe()
+		handler.build(m)
+		for _, method := range mt.methods() {
+			m.Method(method, mt.path, handler)
+			m.routes[method+" "+mt.path] = true
+		}
+		m.names[mt.name] = true
+	}
+
+	return nil
 }
@@ -1,5 +1,14 @@
 package ferry
 
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
 type testService struct{}
 
 type testPayload struct {
@@ -15,3 +24,70 @@ type jsonRequest struct {
 }
 
 type empty struct{}
+
+func TestRegister(t *testing.T) {
+	t.Run("registers handlers with options", func(t *testing.T) {
+		router := NewRouter()
+		svc := testService{}
+		err := router.Register(
+			Procedure(svc.TestProcedureWithoutParams, Name("Renamed")),
+			Procedure(svc.TestProcedureWithParams, Path("greetings/hello"), Method(http.MethodPut)),
+			Stream(func(ctx context.Context, r *queryRequest) (<-chan Event[testPayload], error) {
+				return svc.StreamOneEvent(ctx, r)
+			}, Name("Anonymous")),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := map[string]bool{
+			"POST /Renamed":        true,
+			"PUT /greetings/hello": true,
+			"GET /Anonymous":       true,
+			"POST /Anonymous":      true,
+		}
+		chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			if !expected[method+" "+route] {
+				t.Errorf("unexpected route %s %s", method, route)
+			}
+			delete(expected, method+" "+route)
+			return nil
+		})
+
+		if len(expected) != 0 {
+			t.Errorf("routes not registered: %v", expected)
+		}
+	})
+
+	t.Run("rejects duplicate names", func(t *testing.T) {
+		router := NewRouter()
+		svc := testService{}
+		if err := router.Register(Procedure(svc.TestProcedureWithParams)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		err := router.Register(
+			Procedure(svc.TestProcedureWithoutParams),
+			Procedure(svc.TestProcedureWithoutParams, Name("TestProcedureWithParams"), Path("/Other")),
+		)
+		if err == nil || !strings.Contains(err.Error(), "same name") {
+			t.Errorf("expected duplicate name error, got %v", err)
+		}
+
+		if len(router.Routes()) != 1 {
+			t.Errorf("expected only first handler to be registered, got %d routes", len(router.Routes()))
+		}
+	})
+
+	t.Run("rejects duplicate routes", func(t *testing.T) {
+		router := NewRouter()
+		svc := testService{}
+		err := router.Register(
+			Procedure(svc.TestProcedureWithParams),
+			Procedure(svc.TestProcedureWithoutParams, Path("/TestProcedureWithParams")),
+		)
+		if err == nil || !strings.Contains(err.Error(), "route POST /TestProcedureWithParams") {
+			t.Errorf("expected duplicate route error, got %v", err)
+		}
+	})
+}
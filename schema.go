@@ -0,0 +1,352 @@
+package ferry
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaDialect is the JSON Schema draft used by root schemas.
+const schemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Schema is JSON Schema describing the shape of JSON encoded Go type.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	ContentEncoding      string             `json:"contentEncoding,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	MinProperties        *int               `json:"minProperties,omitempty"`
+	MaxProperties        *int               `json:"maxProperties,omitempty"`
+	OneOf                []*Schema          `json:"oneOf,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// SchemaOf returns JSON Schema (draft 2020-12) of JSON representation of T.
+// Named struct types are described in "$defs" and referenced with "$ref".
+func SchemaOf[T any]() *Schema {
+	return rootSchema(reflect.TypeOf(new(T)).Elem())
+}
+
+// rootSchema returns self-contained schema of the given type.
+// References to the type itself point to the document root.
+func rootSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	g := newSchemaGenerator("#/$defs/")
+
+	var s *Schema
+	if t.Kind() == reflect.Struct && t.Name() != "" && t != timeType && !isBinary(t) {
+		g.root = t
+		s = g.object(t)
+	} else {
+		s = g.schema(t)
+	}
+
+	s.Schema = schemaDialect
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+
+	return s
+}
+
+// schemaGenerator builds schemas for Go types.
+// Named struct types are collected in defs and referenced with refPrefix.
+type schemaGenerator struct {
+	refPrefix string
+	defs      map[string]*Schema
+	names     *typeNames
+	root      reflect.Type
+}
+
+func newSchemaGenerator(refPrefix string) *schemaGenerator {
+	return &schemaGenerator{
+		refPrefix: refPrefix,
+		defs:      make(map[string]*Schema),
+		names:     newTypeNames(),
+	}
+}
+
+// schema returns schema of JSON representation of the given type.
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if isBinary(t) {
+		return &Schema{Type: "string", Format: "binary"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int, reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := float64(0)
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings
+			return &Schema{Type: "string", ContentEncoding: "base64"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return g.ref(t)
+	case reflect.Interface:
+		types := variantsOf(t)
+		if len(types) == 0 {
+			// interfaces without registered variants can hold any value
+			return &Schema{}
+		}
+		s := &Schema{}
+		for _, variant := range types {
+			s.OneOf = append(s.OneOf, g.schema(variant))
+		}
+		return s
+	default:
+		return &Schema{}
+	}
+}
+
+// ref stores named type schema in definitions and returns reference to it.
+func (g *schemaGenerator) ref(t reflect.Type) *Schema {
+	if t == g.root {
+		return &Schema{Ref: "#"}
+	}
+
+	name := g.names.of(t)
+	if _, ok := g.defs[name]; !ok {
+		// placeholder prevents infinite recursion on self-referencing types
+		g.defs[name] = &Schema{}
+		*g.defs[name] = *g.object(t)
+	}
+
+	return &Schema{Ref: g.refPrefix + name}
+}
+
+// object returns schema of struct type with its exported fields as properties.
+// Fields with `validate` tag rules are constrained accordingly.
+func (g *schemaGenerator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for _, field := range jsonFields(t) {
+		property := g.schema(field.Type)
+		if field.Quoted {
+			property = &Schema{Type: "string"}
+		}
+
+		rules := parseRules(field.Validate)
+		constrain(property, field.Type, rules)
+
+		s.Properties[field.Name] = property
+		if !field.OmitEmpty || hasRule(rules, "required") {
+			s.Required = append(s.Required, field.Name)
+		}
+	}
+	sort.Strings(s.Required)
+
+	return s
+}
+
+// jsonField is a struct field as it is seen by encoding/json.
+type jsonField struct {
+	Name      string
+	Type      reflect.Type
+	OmitEmpty bool
+	// Quoted is true if numeric or boolean field is encoded as JSON string.
+	Quoted bool
+	// Validate is the `validate` tag of the field.
+	Validate string
+	// Protobuf is the `protobuf` tag of fields of generated Protocol Buffers messages.
+	Protobuf string
+}
+
+// jsonFields returns fields of struct type named and omitted the same way encoding/json does it.
+// Fields of embedded structs are promoted, fields of the parent struct take precedence over promoted ones.
+func jsonFields(t reflect.Type) []jsonField {
+	fields := make([]jsonField, 0, t.NumField())
+	promoted := make([]jsonField, 0)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				for _, f := range jsonFields(embedded) {
+					// fields of embedded pointers may be absent
+					f.OmitEmpty = f.OmitEmpty || field.Type.Kind() == reflect.Ptr
+					promoted = append(promoted, f)
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		quoted := false
+		if hasOption(options, "string") {
+			switch field.Type.Kind() {
+			case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+				quoted = true
+			}
+		}
+
+		fields = append(fields, jsonField{
+			Name:      name,
+			Type:      field.Type,
+			OmitEmpty: hasOption(options, "omitempty"),
+			Quoted:    quoted,
+			Validate:  field.Tag.Get("validate"),
+			Protobuf:  field.Tag.Get("protobuf"),
+		})
+	}
+
+	for _, p := range promoted {
+		shadowed := false
+		for _, f := range fields {
+			if f.Name == p.Name {
+				shadowed = true
+				break
+			}
+		}
+
+		if !shadowed {
+			fields = append(fields, p)
+		}
+	}
+
+	return fields
+}
+
+// constrain adds validation rules to schema of type t.
+func constrain(s *Schema, t reflect.Type, rules []rule) {
+	kind := indirectType(t).Kind()
+
+	for i, r := range rules {
+		switch r.name {
+		case "dive":
+			elem := s.Items
+			if kind == reflect.Map {
+				elem = s.AdditionalProperties
+			}
+			if elem != nil {
+				constrain(elem, indirectType(t).Elem(), rules[i+1:])
+			}
+			return
+		case "min", "max":
+			limit, err := strconv.ParseFloat(r.param, 64)
+			if err != nil {
+				continue
+			}
+			count := int(limit)
+			switch {
+			case kind == reflect.String && r.name == "min":
+				s.MinLength = &count
+			case kind == reflect.String:
+				s.MaxLength = &count
+			case (kind == reflect.Slice || kind == reflect.Array) && r.name == "min":
+				s.MinItems = &count
+			case kind == reflect.Slice || kind == reflect.Array:
+				s.MaxItems = &count
+			case kind == reflect.Map && r.name == "min":
+				s.MinProperties = &count
+			case kind == reflect.Map:
+				s.MaxProperties = &count
+			case r.name == "min":
+				s.Minimum = &limit
+			default:
+				s.Maximum = &limit
+			}
+		case "pattern":
+			s.Pattern = r.param
+		case "oneof":
+			for _, option := range strings.Fields(r.param) {
+				if number, err := strconv.ParseFloat(option, 64); err == nil && isNumber(kind) {
+					s.Enum = append(s.Enum, number)
+				} else {
+					s.Enum = append(s.Enum, option)
+				}
+			}
+		case "email", "uuid":
+			s.Format = r.name
+		}
+	}
+}
+
+// hasRule reports whether rules contain rule with given name, not counting rules of dived elements.
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == "dive" {
+			return false
+		}
+		if r.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasOption reports whether comma separated tag options contain the given option.
+func hasOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,148 @@
+package ferry
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type treeNode struct {
+	Name     string      `json:"name"`
+	Parent   *treeNode   `json:"parent,omitempty"`
+	Children []*treeNode `json:"children"`
+	Leaf     leaf        `json:"leaf"`
+}
+
+type leaf struct {
+	Value int `json:"value"`
+}
+
+type embeddedRequest struct {
+	leaf
+	Name    string    `json:"name"`
+	Value   string    `json:"value"`
+	Created time.Time `json:"created,omitempty"`
+	Count   int       `json:"count,string"`
+	Skipped string    `json:"-"`
+	private string
+}
+
+// StringValue has the same name as wrapperspb.StringValue.
+type StringValue struct {
+	Text string `json:"text"`
+}
+
+type collidingNames struct {
+	Local  StringValue             `json:"local"`
+	Remote *wrapperspb.StringValue `json:"remote"`
+}
+
+func TestTypeNameCollisions(t *testing.T) {
+	t.Run("schema", func(t *testing.T) {
+		s := SchemaOf[collidingNames]()
+
+		if s.Properties["local"].Ref != "#/$defs/StringValue" || s.Properties["remote"].Ref != "#/$defs/WrapperspbStringValue" {
+			t.Errorf("unexpected references, got %s and %s", s.Properties["local"].Ref, s.Properties["remote"].Ref)
+		}
+
+		if _, ok := s.Defs["StringValue"].Properties["text"]; !ok {
+			t.Errorf("unexpected local definition, got %+v", s.Defs["StringValue"])
+		}
+	})
+
+	t.Run("typescript", func(t *testing.T) {
+		g := newTypeScriptGenerator()
+		g.typeOf(reflect.TypeOf(collidingNames{}))
+
+		if !strings.Contains(g.decls["collidingNames"], "remote: WrapperspbStringValue | null;") || g.decls["StringValue"] == g.decls["WrapperspbStringValue"] {
+			t.Errorf("unexpected declarations, got %v", g.decls)
+		}
+	})
+
+	t.Run("proto", func(t *testing.T) {
+		g := newProtoGenerator()
+		g.message(reflect.TypeOf(collidingNames{}), "Request")
+
+		if g.messages["collidingNames"] != "{\n  StringValue local = 1;\n  WrapperspbStringValue remote = 2;\n}" {
+			t.Errorf("unexpected messages, got %v", g.messages)
+		}
+	})
+
+	t.Run("qualifies function scoped types", func(t *testing.T) {
+		type StringValue struct{}
+
+		names := newTypeNames()
+		names.of(reflect.TypeOf(collidingNames{}.Local))
+		if name := names.of(reflect.TypeOf(StringValue{})); name != "FerryStringValue" {
+			t.Errorf("unexpected name, got %q", name)
+		}
+	})
+}
+
+func TestSchemaOf(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schema   *Schema
+		expected string
+	}{
+		{
+			name:     "exotic",
+			schema:   SchemaOf[exoticJSONRequest](),
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"data":{"type":"string","contentEncoding":"base64"},"float":{"type":"number","format":"double"},"number":{"type":"integer","minimum":0}},"required":["data","float","number"]}`,
+		},
+		{
+			name:     "empty",
+			schema:   SchemaOf[empty](),
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object"}`,
+		},
+		{
+			name:     "recursive",
+			schema:   SchemaOf[treeNode](),
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"children":{"type":"array","items":{"$ref":"#"}},"leaf":{"$ref":"#/$defs/leaf"},"name":{"type":"string"},"parent":{"$ref":"#"}},"required":["children","leaf","name"],"$defs":{"leaf":{"type":"object","properties":{"value":{"type":"integer","format":"int64"}},"required":["value"]}}}`,
+		},
+		{
+			name:     "slice of named types",
+			schema:   SchemaOf[[]treeNode](),
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"array","items":{"$ref":"#/$defs/treeNode"},"$defs":{"leaf":{"type":"object","properties":{"value":{"type":"integer","format":"int64"}},"required":["value"]},"treeNode":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/treeNode"}},"leaf":{"$ref":"#/$defs/leaf"},"name":{"type":"string"},"parent":{"$ref":"#/$defs/treeNode"}},"required":["children","leaf","name"]}}}`,
+		},
+		{
+			name:     "embedded and skipped fields",
+			schema:   SchemaOf[embeddedRequest](),
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","properties":{"count":{"type":"string"},"created":{"type":"string","format":"date-time"},"name":{"type":"string"},"value":{"type":"string"}},"required":["count","name","value"]}`,
+		},
+		{
+			name:     "map",
+			schema:   SchemaOf[map[string][]float32](),
+			expected: `{"$schema":"https://json-schema.org/draft/2020-12/schema","type":"object","additionalProperties":{"type":"array","items":{"type":"number","format":"float"}}}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			content, err := json.Marshal(testCase.schema)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if string(content) != testCase.expected {
+				t.Errorf("unexpected schema, got %s", content)
+			}
+		})
+	}
+}
+
+func TestSchemaConstraints(t *testing.T) {
+	content, err := json.Marshal(SchemaOf[validatedRequest]().Properties)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := `{"address":{"$ref":"#/$defs/validatedAddress"},"age":{"type":"integer","format":"int64","minimum":18,"maximum":130},"code":{"type":"string","pattern":"^[A-Z]{2,3}$"},"contacts":{"type":"array","items":{"$ref":"#/$defs/validatedPerson"}},"email":{"type":"string","format":"email"},"id":{"type":"string","format":"uuid"},"name":{"type":"string","minLength":3,"maxLength":10},"role":{"type":"string","enum":["admin","user"]},"tags":{"type":"array","items":{"type":"string","minLength":2},"maxItems":2}}`
+	if string(content) != expected {
+		t.Errorf("unexpected schema, got %s", content)
+	}
+}
@@ -0,0 +1,144 @@
+package ferry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// RegisterService registers every method of interface I implemented by impl to the Router.
+// Methods are registered as Procedure, Stream, ClientStream or BidiStream depending on their signature, route names are taken from interface.
+// If some methods have unsupported signatures, error listing them is returned and nothing is registered.
+func RegisterService[I any](router Router, impl I) error {
+	iface := reflect.TypeOf((*I)(nil)).Elem()
+	if iface.Kind() != reflect.Interface {
+		return fmt.Errorf("can not register %s: service must be an interface", iface)
+	}
+
+	value := reflect.ValueOf(impl)
+	if !value.IsValid() {
+		return fmt.Errorf("can not register %s: implementation is nil", iface)
+	}
+
+	handlers := make([]Handler, 0, iface.NumMethod())
+	unsupported := make([]string, 0)
+	for i := 0; i < iface.NumMethod(); i++ {
+		method := iface.Method(i)
+		handler, err := methodHandler(method.Name, value.MethodByName(method.Name))
+		if err != nil {
+			unsupported = append(unsupported, fmt.Sprintf("%s: %v", method.Name, err))
+			continue
+		}
+
+		handlers = append(handlers, handler)
+	}
+
+	if len(unsupported) > 0 {
+		return fmt.Errorf("can not register %s, unsupported methods: %s", iface, strings.Join(unsupported, "; "))
+	}
+
+	return router.Register(handlers...)
+}
+
+// methodHandler creates Procedure, Stream, ClientStream or BidiStream handler from method value by inspecting its signature.
+func methodHandler(name string, fn reflect.Value) (Handler, error) {
+	t := fn.Type()
+	if t.NumIn() != 2 || t.NumOut() != 2 {
+		return nil, errors.New("expected two parameters and two results")
+	}
+
+	if t.In(0) != contextType {
+		return nil, errors.New("first parameter must be context.Context")
+	}
+
+	if t.Out(1) != errorType {
+		return nil, errors.New("last result must be error")
+	}
+
+	if t.In(1).Kind() == reflect.Chan && t.In(1).ChanDir() == reflect.RecvDir {
+		return channelMethodHandler(name, fn)
+	}
+
+	if t.In(1).Kind() != reflect.Ptr || t.In(1).Elem().Kind() != reflect.Struct {
+		return nil, errors.New("second parameter must be a pointer to struct or receive-only channel")
+	}
+
+	request, response := t.In(1).Elem(), t.Out(0)
+	call := func(ctx context.Context, r interface{}) (reflect.Value, error) {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(r)})
+		err, _ := out[1].Interface().(error)
+		return out[0], err
+	}
+
+	switch {
+	case response.Kind() == reflect.Ptr:
+		mt, err := buildMeta(name, request, response.Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return newProcedure(mt, func(ctx context.Context, r interface{}) (interface{}, error) {
+			res, err := call(ctx, r)
+			return res.Interface(), err
+		}), nil
+	case isEventChan(response):
+		mt, err := buildMeta(name, request, eventPayload(response))
+		if err != nil {
+			return nil, err
+		}
+
+		return newStream(mt, call), nil
+	default:
+		return nil, errors.New("first result must be a pointer or <-chan ferry.Event")
+	}
+}
+
+// channelMethodHandler creates ClientStream or BidiStream handler from method value which receives channel.
+func channelMethodHandler(name string, fn reflect.Value) (Handler, error) {
+	t := fn.Type()
+	if t.Out(0).Kind() == reflect.Ptr {
+		mt, err := buildMeta(name, t.In(1).Elem(), t.Out(0).Elem())
+		if err != nil {
+			return nil, err
+		}
+
+		return newClientStream(mt, func(ctx context.Context, in reflect.Value) (interface{}, error) {
+			out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), in})
+			err, _ := out[1].Interface().(error)
+			return out[0].Interface(), err
+		}), nil
+	}
+
+	if !isEventChan(t.Out(0)) {
+		return nil, errors.New("first result of channel receiving method must be a pointer or <-chan ferry.Event")
+	}
+
+	mt, err := buildMeta(name, t.In(1).Elem(), eventPayload(t.Out(0)))
+	if err != nil {
+		return nil, err
+	}
+
+	return newBidiStream(mt, func(ctx context.Context, in reflect.Value) (reflect.Value, error) {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), in})
+		err, _ := out[1].Interface().(error)
+		return out[0], err
+	}), nil
+}
+
+// isEventChan reports whether type is receive-only channel of Event.
+func isEventChan(t reflect.Type) bool {
+	return t.Kind() == reflect.Chan && t.ChanDir() == reflect.RecvDir && t.Elem().Implements(streamEventType)
+}
+
+// eventPayload returns payload type of Event channel.
+func eventPayload(t reflect.Type) reflect.Type {
+	payload, _ := t.Elem().FieldByName("Payload")
+	return payload.Type.Elem()
+}
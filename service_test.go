@@ -0,0 +1,91 @@
+package ferry
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type testAPI interface {
+	TestProcedureWithParams(context.Context, *jsonRequest) (*testPayload, error)
+	StreamOneEvent(context.Context, *queryRequest) (<-chan Event[testPayload], error)
+}
+
+type invalidAPI interface {
+	TestProcedureWithParams(context.Context, *jsonRequest) (*testPayload, error)
+	Invalid(context.Context, jsonRequest) (*testPayload, error)
+	Unsupported(context.Context, *jsonRequest) (testPayload, error)
+}
+
+func (t testService) Invalid(ctx context.Context, r jsonRequest) (*testPayload, error) {
+	return &testPayload{}, nil
+}
+
+func (t testService) Unsupported(ctx context.Context, r *jsonRequest) (testPayload, error) {
+	return testPayload{}, nil
+}
+
+func TestRegisterService(t *testing.T) {
+	t.Run("registers procedures and streams", func(t *testing.T) {
+		router := NewRouter()
+		if err := RegisterService[testAPI](router, testService{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/TestProcedureWithParams", bytes.NewReader([]byte(`{"value":"test_data"}`)))
+		r.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusOK || rr.Body.String() != `{"value":"test_data"}` {
+			t.Errorf("unexpected response, got %d %s", rr.Code, rr.Body.String())
+		}
+
+		rr = httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/StreamOneEvent?value=test", nil))
+
+		content, err := io.ReadAll(rr.Body)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+
+		expected := `event: keep-alive
+
+id: 1
+event: testPayload
+data: {"value":"test"}
+
+`
+		if string(content) != expected {
+			t.Errorf("unexpected response, got %s", content)
+		}
+	})
+
+	t.Run("rejects unsupported methods", func(t *testing.T) {
+		router := NewRouter()
+		err := RegisterService[invalidAPI](router, testService{})
+		if err == nil {
+			t.Fatal("expected error")
+		}
+
+		for _, method := range []string{"Invalid", "Unsupported"} {
+			if !strings.Contains(err.Error(), method+":") {
+				t.Errorf("expected error to mention %s, got %v", method, err)
+			}
+		}
+
+		if len(router.Routes()) != 0 {
+			t.Errorf("expected no routes to be registered, got %d", len(router.Routes()))
+		}
+	})
+
+	t.Run("rejects non interface types", func(t *testing.T) {
+		if err := RegisterService[testService](NewRouter(), testService{}); err == nil {
+			t.Error("expected error")
+		}
+	})
+}
@@ -4,32 +4,72 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"reflect"
+	"sort"
+	"strings"
 	"time"
 )
 
-// Event carries payload and event ID.
+// Event carries payload and event ID. Event is named after the type of payload, unless Type is set.
+// Stream function can set Err to terminate the stream, error is sent to client as "error" event.
+// Client receives Event with Err set when stream is terminated by server.
 type Event[P any] struct {
 	ID      string
+	Type    string
 	Payload *P
+	Err     error
 }
 
+// event returns ID, payload and error of the Event, it allows to handle events regardless of payload type.
+func (e Event[P]) event() (string, interface{}, error) { return e.ID, e.Payload, e.Err }
+
+// eventType returns Type of the Event.
+func (e Event[P]) eventType() string { return e.Type }
+
+// streamEvent is implemented by Event of any payload type.
+type streamEvent interface {
+	event() (id string, payload interface{}, err error)
+	eventType() string
+}
+
+var streamEventType = reflect.TypeOf((*streamEvent)(nil)).Elem()
+
 // Stream will return Handler which can be used to register SSE stream in Router.
+// Stream is sent as application/x-ndjson to clients which accept it instead of text/event-stream.
+// Unless Method option is set, Stream is registered with GET method decoding request from query
+// and POST method decoding request from JSON body. Requests of POST, PUT and PATCH methods are decoded from body,
+// requests of other methods from query.
 // Stream function MUST close channel when context is cancelled.
-// Handler will panic if context is cancelled and channel is not closed.
-// Provided argument MUST be a function which has a receiver.
-func Stream[Req any, Msg any](fn func(ctx context.Context, r *Req) (<-chan Event[Msg], error)) Handler {
-	payloadType := reflect.TypeOf(new(Msg)).Elem().Name()
+// If channel is not closed in time after stream has finished, leak is reported to LeakPolicy.
+// Handler is named after the function, anonymous functions get generated names, so they should be named with Name option.
+func Stream[Req any, Msg any](fn func(ctx context.Context, r *Req) (<-chan Event[Msg], error), options ...func(*meta)) Handler {
+	mt := handlerMeta(fn, reflect.TypeOf(new(Req)).Elem(), reflect.TypeOf(new(Msg)).Elem(), options)
 
-	mt, err := buildMeta(fn, new(Req))
-	if err != nil {
-		panic(err)
+	return newStream(mt, func(ctx context.Context, r interface{}) (reflect.Value, error) {
+		events, err := fn(ctx, r.(*Req))
+		return reflect.ValueOf(events), err
+	})
+}
+
+// newStream creates stream handler. Open receives pointer to decoded request of mt.request type
+// and returns channel of Event with mt.response payload.
+func newStream(mt meta, open func(ctx context.Context, r interface{}) (reflect.Value, error)) *streamHandler {
+	if mt.method == "" {
+		// unless method is set explicitly, stream also accepts request as JSON body
+		mt.also = []string{http.MethodPost}
 	}
+	mt.setDefaults(http.MethodGet)
+	payloadType := eventName(mt.response)
+	multiType := mt.response.Kind() == reflect.Interface
 
 	return &streamHandler{
 		meta: mt,
 		builder: func(m *mux) http.HandlerFunc {
+			interceptors, info := m.streamInterceptors, streamInfo(mt)
+			config := m.stream.merge(mt.stream)
+
 			return func(w http.ResponseWriter, r *http.Request) {
 				flusher, ok := w.(http.Flusher)
 				if !ok {
@@ -40,62 +80,360 @@ func Stream[Req any, Msg any](fn func(ctx context.Context, r *Req) (<-chan Event
 					return
 				}
 
-				var reqValue Req
-				if err := decodeQuery(r, &reqValue); err != nil {
+				reqValue := reflect.New(mt.request).Interface()
+				bindLastEventID(r, reqValue)
+				if hasBody(r.Method) {
+					if err := decodeBody(r, reqValue); err != nil {
+						m.errHandler(w, r, err)
+						return
+					}
+					defer closeRequestFiles(reqValue)
+				} else if err := decodeQuery(r, reqValue); err != nil {
 					m.errHandler(w, r, err)
 					return
 				}
 
-				w.Header().Set("Content-Type", "text/event-stream")
-				w.Header().Set("Cache-Control", "no-cache")
-				w.Header().Set("Connection", "keep-alive")
-
 				ctx := createContext(w, r)
-				events, err := fn(ctx, &reqValue)
+				intercept, err := interceptStream(ctx, interceptors, info, reqValue)
 				if err != nil {
 					m.errHandler(w, r, err)
 					return
 				}
 
-				// respond immediately with keep-alive message
-				if _, err := fmt.Fprintf(w, "event: keep-alive\n\n"); err != nil {
-					m.errHandler(w, r, fmt.Errorf("write initial keep-alive: %w", err))
+				if !m.streams.start() {
+					m.errHandler(w, r, errShuttingDown)
+					return
+				}
+				defer m.streams.finish()
+
+				events, err := open(ctx, reqValue)
+				if err != nil {
+					m.errHandler(w, r, err)
+					return
+				}
+
+				// stream function is expected to close the channel when stream is finished
+				closed := false
+				defer func() {
+					if !closed {
+						supervise(events, m.leakTimeout, m.leakPolicy, info)
+					}
+				}()
+
+				// from now on errors are sent to client as "error" events
+				format := negotiateFormat(r, config)
+				w.Header().Set("Content-Type", format.contentType())
+				w.Header().Set("Cache-Control", "no-cache")
+				w.Header().Set("Connection", "keep-alive")
+
+				// respond immediately, so client knows stream is open
+				if err := format.open(w); err != nil {
 					return
 				}
 				flusher.Flush()
 
+				replayed := make(map[string]bool)
+				// send writes event to the stream, it returns false if stream has to be finished.
+				send := func(received interface{}) bool {
+					event, err := intercept(received)
+					if err != nil {
+						format.fail(w, err)
+						return false
+					}
+					if event == nil {
+						return true
+					}
+
+					id, payload, err := event.(streamEvent).event()
+					if err != nil {
+						format.fail(w, err)
+						return false
+					}
+					if replayed[id] {
+						// client already received this event from replay buffer
+						return true
+					}
+
+					data, err := json.Marshal(payload)
+					if err != nil {
+						format.fail(w, fmt.Errorf("encode message: %w", err))
+						return false
+					}
+
+					name := frameName(event.(streamEvent), payload, payloadType, multiType)
+					return format.event(w, id, name, data) == nil
+				}
+
+				// replay events missed by reconnecting client
+				key := ""
+				if config.replay != nil {
+					key = config.replayKey(r, reqValue)
+				}
+				if lastEventID := LastEventID(ctx); config.replay != nil && lastEventID != "" {
+					for _, event := range config.replay.Since(key, lastEventID) {
+						if !send(event) {
+							flusher.Flush()
+							return
+						}
+						id, _, _ := event.(streamEvent).event()
+						replayed[id] = true
+					}
+					flusher.Flush()
+				}
+
+				heartbeat := time.NewTimer(config.heartbeat)
+				defer heartbeat.Stop()
+
+				cases := []reflect.SelectCase{
+					{Dir: reflect.SelectRecv, Chan: events},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(heartbeat.C)},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+					{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(m.streams.done)},
+				}
+
+				if config.maxLifetime > 0 {
+					lifetime := time.NewTimer(config.maxLifetime)
+					defer lifetime.Stop()
+					cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(lifetime.C)})
+				}
+
 				for {
-					select {
-					case event, ok := <-events:
+					chosen, received, ok := reflect.Select(cases)
+
+					switch chosen {
+					case 0:
 						if !ok {
+							closed = true
 							return
 						}
-						payload, err := json.Marshal(event.Payload)
-						if err != nil {
-							m.errHandler(w, r, fmt.Errorf("encode message: %w", err))
-							return
+						if config.replay != nil {
+							if id, _, err := received.Interface().(streamEvent).event(); id != "" && err == nil {
+								config.replay.Store(key, received.Interface())
+							}
 						}
-						if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, payloadType, payload); err != nil {
-							m.errHandler(w, r, fmt.Errorf("write message: %w", err))
+						if !send(received.Interface()) {
+							flusher.Flush()
 							return
 						}
-					case <-time.After(5 * time.Second):
-						select {
-						case <-ctx.Done():
-							// panic, channel MUST be closed when context is cancelled
-							panic(fmt.Sprintf("%q stream channel is not closed", mt.name))
-						default:
-							// keep connection alive
-							if _, err := fmt.Fprintf(w, "event: keep-alive\n\n"); err != nil {
-								m.errHandler(w, r, fmt.Errorf("write keep-alive: %w", err))
-								return
-							}
+					case 1:
+						// keep connection alive
+						if err := format.heartbeat(w); err != nil {
+							return
 						}
+					case 2:
+						// client has gone away
+						return
+					default:
+						// server is shutting down or stream reached its maximum lifetime, client is expected to reconnect
+						return
 					}
 
 					flusher.Flush()
+					resetTimer(heartbeat, config.heartbeat)
 				}
 			}
 		},
 	}
 }
+
+// frameName returns name of the event sent to client. It is Type of the event if it is set,
+// name of the payload variant for multi-type streams or name of the payload type otherwise.
+func frameName(event streamEvent, payload interface{}, payloadType string, multiType bool) string {
+	switch {
+	case event.eventType() != "":
+		return event.eventType()
+	case multiType:
+		return dynamicEventName(payload)
+	default:
+		return payloadType
+	}
+}
+
+// streamFormat writes stream to client in the format negotiated by Accept header.
+type streamFormat interface {
+	contentType() string
+	// open writes the beginning of the stream.
+	open(w io.Writer) error
+	// heartbeat writes message which keeps connection alive.
+	heartbeat(w io.Writer) error
+	// event writes event with JSON encoded payload.
+	event(w io.Writer, id, name string, data []byte) error
+	// fail writes error as "error" event.
+	fail(w io.Writer, err error)
+}
+
+// negotiateFormat picks stream format by quality values of Accept header. Formats of equal quality are preferred
+// in the order they are listed, text/event-stream is used if neither text/event-stream nor application/x-ndjson is accepted.
+func negotiateFormat(r *http.Request, config streamConfig) streamFormat {
+	ranges := acceptRanges(r)
+	formats := []streamFormat{sseFormat{config: config}, ndjsonFormat{}}
+	sort.SliceStable(formats, func(i, j int) bool {
+		return listed(ranges, formats[i].contentType()) < listed(ranges, formats[j].contentType())
+	})
+
+	var (
+		best            streamFormat = sseFormat{config: config}
+		bestQuality     float64
+		bestSpecificity int
+	)
+	for _, format := range formats {
+		quality, specificity := acceptance(ranges, format.contentType())
+		if quality > bestQuality || (quality == bestQuality && quality > 0 && specificity > bestSpecificity) {
+			best, bestQuality, bestSpecificity = format, quality, specificity
+		}
+	}
+
+	return best
+}
+
+// listed returns position of media type in Accept header, or number of ranges if it is not listed explicitly.
+func listed(ranges []mediaRange, mediaType string) int {
+	for i, rng := range ranges {
+		if strings.EqualFold(rng.mediaType, mediaType) {
+			return i
+		}
+	}
+
+	return len(ranges)
+}
+
+// sseFormat writes stream as text/event-stream.
+type sseFormat struct {
+	config streamConfig
+}
+
+func (f sseFormat) contentType() string { return "text/event-stream" }
+
+func (f sseFormat) open(w io.Writer) error {
+	if f.config.retry > 0 {
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", f.config.retry.Milliseconds()); err != nil {
+			return err
+		}
+	}
+
+	return f.heartbeat(w)
+}
+
+func (f sseFormat) heartbeat(w io.Writer) error {
+	_, err := io.WriteString(w, f.config.heartbeatStyle.frame())
+	return err
+}
+
+func (f sseFormat) event(w io.Writer, id, name string, data []byte) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", id, name, data)
+	return err
+}
+
+// fail encodes error the same way DefaultErrorHandler encodes it, unexpected errors are hidden from client.
+func (f sseFormat) fail(w io.Writer, err error) {
+	fmt.Fprintf(w, "event: error\ndata: %s\n\n", errorData(err))
+}
+
+// ndjsonFormat writes stream as application/x-ndjson, every event is a single line of JSON encoded eventMessage.
+// Heartbeats are written as empty lines.
+type ndjsonFormat struct{}
+
+func (f ndjsonFormat) contentType() string { return "application/x-ndjson" }
+
+func (f ndjsonFormat) open(w io.Writer) error { return f.heartbeat(w) }
+
+func (f ndjsonFormat) heartbeat(w io.Writer) error {
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (f ndjsonFormat) event(w io.Writer, id, name string, data []byte) error {
+	line, err := json.Marshal(eventMessage{ID: id, Event: name, Data: data})
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s\n", line)
+	return err
+}
+
+// fail encodes error the same way DefaultErrorHandler encodes it, unexpected errors are hidden from client.
+func (f ndjsonFormat) fail(w io.Writer, err error) {
+	f.event(w, "", "error", errorData(err))
+}
+
+// errorData returns JSON encoded error which is sent to client as "error" event.
+func errorData(err error) []byte {
+	data, err := json.Marshal(newErrorBody(err))
+	if err != nil {
+		// error details can not be encoded, fall back to generic error
+		data, _ = json.Marshal(newErrorBody(nil))
+	}
+
+	return data
+}
+
+// HeartbeatStyle defines how heartbeats keeping stream connection alive are written.
+type HeartbeatStyle int
+
+const (
+	// HeartbeatEvent writes heartbeats as "keep-alive" events. This is the default style.
+	HeartbeatEvent HeartbeatStyle = iota + 1
+	// HeartbeatComment writes heartbeats as comment lines which are ignored by clients.
+	HeartbeatComment
+)
+
+// frame returns heartbeat in text/event-stream format.
+func (s HeartbeatStyle) frame() string {
+	if s == HeartbeatComment {
+		return ": keep-alive\n\n"
+	}
+
+	return "event: keep-alive\n\n"
+}
+
+// streamConfig configures Stream handlers. Zero values are not set.
+type streamConfig struct {
+	heartbeat      time.Duration
+	heartbeatStyle HeartbeatStyle
+	retry          time.Duration
+	maxLifetime    time.Duration
+	replay         ReplayBuffer
+	replayKey      ReplayKeyFunc
+}
+
+// merge returns config with values overridden by values set in other config.
+func (c streamConfig) merge(other streamConfig) streamConfig {
+	if other.heartbeat > 0 {
+		c.heartbeat = other.heartbeat
+	}
+
+	if other.heartbeatStyle != 0 {
+		c.heartbeatStyle = other.heartbeatStyle
+	}
+
+	if other.retry > 0 {
+		c.retry = other.retry
+	}
+
+	if other.maxLifetime > 0 {
+		c.maxLifetime = other.maxLifetime
+	}
+
+	if other.replay != nil {
+		c.replay = other.replay
+	}
+
+	if other.replayKey != nil {
+		c.replayKey = other.replayKey
+	}
+
+	return c
+}
+
+// resetTimer stops timer, drains its channel if it has fired and resets it to given duration.
+func resetTimer(timer *time.Timer, d time.Duration) {
+	if !timer.Stop() {
+		select {
+		case <-timer.C:
+		default:
+		}
+	}
+
+	timer.Reset(d)
+}
@@ -2,10 +2,12 @@ package ferry
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -80,6 +82,184 @@ func (s testService) LeakyStream(ctx context.Context, r *empty) (<-chan Event[em
 	return c, nil
 }
 
+func (s testService) FailingStream(ctx context.Context, r *queryRequest) (<-chan Event[testPayload], error) {
+	if r.Value == "reject" {
+		return nil, PermissionDenied("rejected")
+	}
+
+	c := make(chan Event[testPayload])
+
+	go func() {
+		defer close(c)
+
+		events := []Event[testPayload]{
+			{ID: "1", Payload: &testPayload{Value: r.Value}},
+			{ID: "2", Err: NotFound("gone")},
+			{ID: "3", Payload: &testPayload{Value: r.Value}},
+		}
+		if r.Value == "unexpected" {
+			events[1].Err = errors.New("database is down")
+		}
+
+		for _, event := range events {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- event:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+func TestStreamErrors(t *testing.T) {
+	router := NewRouter()
+	svc := testService{}
+	router.Register(Stream(svc.FailingStream))
+
+	t.Run("rejects stream with error response", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/FailingStream?value=reject", nil))
+
+		if rr.Code != http.StatusForbidden {
+			t.Errorf("expected status code %d, got %d", http.StatusForbidden, rr.Code)
+		}
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "application/json; charset=utf-8" {
+			t.Errorf("unexpected content type, got %q", contentType)
+		}
+	})
+
+	t.Run("terminates stream with error event", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/FailingStream?value=test", nil))
+
+		expected := "event: keep-alive\n\n" +
+			"id: 1\nevent: testPayload\ndata: {\"value\":\"test\"}\n\n" +
+			"event: error\ndata: {\"error\":\"gone\",\"code\":\"not_found\"}\n\n"
+
+		if content := rr.Body.String(); content != expected {
+			t.Errorf("unexpected response, got %q", content)
+		}
+	})
+
+	t.Run("hides unexpected error", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/FailingStream?value=unexpected", nil))
+
+		if content := rr.Body.String(); !strings.HasSuffix(content, "event: error\ndata: {\"error\":\"internal server error\",\"code\":\"internal\"}\n\n") {
+			t.Errorf("unexpected response, got %q", content)
+		}
+	})
+}
+
+func TestStreamFormats(t *testing.T) {
+	router := NewRouter()
+	svc := testService{}
+	router.Register(Stream(svc.FailingStream))
+
+	ndjson := "\n" +
+		"{\"id\":\"1\",\"event\":\"testPayload\",\"data\":{\"value\":\"test\"}}\n" +
+		"{\"event\":\"error\",\"data\":{\"error\":\"gone\",\"code\":\"not_found\"}}\n"
+
+	t.Run("writes newline delimited JSON", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/FailingStream?value=test", nil)
+		r.Header.Set("Accept", "application/x-ndjson")
+		router.ServeHTTP(rr, r)
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "application/x-ndjson" {
+			t.Errorf("unexpected content type, got %q", contentType)
+		}
+
+		if content := rr.Body.String(); content != ndjson {
+			t.Errorf("unexpected response, got %q", content)
+		}
+	})
+
+	t.Run("prefers first accepted format", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/FailingStream?value=test", nil)
+		r.Header.Set("Accept", "text/event-stream, application/x-ndjson")
+		router.ServeHTTP(rr, r)
+
+		if contentType := rr.Header().Get("Content-Type"); contentType != "text/event-stream" {
+			t.Errorf("unexpected content type, got %q", contentType)
+		}
+	})
+
+	t.Run("respects quality values", func(t *testing.T) {
+		tests := map[string]string{
+			"application/x-ndjson;q=0, text/event-stream":   "text/event-stream",
+			"text/event-stream;q=0.5, application/x-ndjson": "application/x-ndjson",
+			"application/x-ndjson, */*;q=0.1":               "application/x-ndjson",
+			"text/*":                                        "text/event-stream",
+		}
+
+		for accept, expected := range tests {
+			rr := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/FailingStream?value=test", nil)
+			r.Header.Set("Accept", accept)
+			router.ServeHTTP(rr, r)
+
+			if contentType := rr.Header().Get("Content-Type"); contentType != expected {
+				t.Errorf("expected %q for %q, got %q", expected, accept, contentType)
+			}
+		}
+	})
+
+	t.Run("accepts request as JSON body", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/FailingStream", strings.NewReader(`{"value":"test"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/x-ndjson")
+		router.ServeHTTP(rr, r)
+
+		if content := rr.Body.String(); content != ndjson {
+			t.Errorf("unexpected response, got %q", content)
+		}
+	})
+
+	t.Run("rejects malformed JSON body", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/FailingStream", strings.NewReader(`{"value":`))
+		r.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rr, r)
+
+		if rr.Code != http.StatusBadRequest {
+			t.Errorf("expected status code %d, got %d", http.StatusBadRequest, rr.Code)
+		}
+	})
+
+	t.Run("decodes body when registered with POST method", func(t *testing.T) {
+		router := NewRouter()
+		router.Register(Stream(svc.FailingStream, Method(http.MethodPost)))
+
+		rr := httptest.NewRecorder()
+		r := httptest.NewRequest("POST", "/FailingStream", strings.NewReader(`{"value":"test"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept", "application/x-ndjson")
+		router.ServeHTTP(rr, r)
+
+		if content := rr.Body.String(); content != ndjson {
+			t.Errorf("unexpected response, got %q", content)
+		}
+	})
+
+	t.Run("is not registered with POST when method is set", func(t *testing.T) {
+		router := NewRouter()
+		router.Register(Stream(svc.FailingStream, Method(http.MethodGet)))
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("POST", "/FailingStream", strings.NewReader(`{}`)))
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d, got %d", http.StatusNotFound, rr.Code)
+		}
+	})
+}
+
 func TestStream(t *testing.T) {
 	t.Run("keep alive messages are sent each 5 seconds", func(t *testing.T) {
 		t.Parallel()
@@ -163,9 +343,38 @@ data: {"value":"test_data"}
 		}
 	})
 
-	t.Run("panics on leaking stream channel", func(t *testing.T) {
+	t.Run("stream options override router options", func(t *testing.T) {
 		t.Parallel()
-		router := NewRouter()
+		router := NewRouter(WithHeartbeat(time.Minute, HeartbeatEvent), WithRetry(3*time.Second))
+		svc := testService{}
+		router.Register(Stream(svc.EmptyStreamForSixSeconds, Heartbeat(time.Second, HeartbeatComment), MaxLifetime(1500*time.Millisecond)))
+		rr := httptest.NewRecorder()
+
+		start := time.Now()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/EmptyStreamForSixSeconds", nil))
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("expected stream to end after max lifetime, took %s", elapsed)
+		}
+
+		expected := `retry: 3000
+
+: keep-alive
+
+: keep-alive
+
+`
+
+		if content := rr.Body.String(); content != expected {
+			t.Errorf("unexpected response, got %s", content)
+		}
+	})
+
+	t.Run("reports leaking stream channel", func(t *testing.T) {
+		t.Parallel()
+		leaks := make(chan string, 1)
+		router := NewRouter(WithLeakPolicy(100*time.Millisecond, func(info StreamInfo) {
+			leaks <- info.Name
+		}))
 		svc := testService{}
 		router.Register(Stream(svc.LeakyStream))
 		rr := httptest.NewRecorder()
@@ -174,13 +383,64 @@ data: {"value":"test_data"}
 		defer cancel()
 		r = r.WithContext(ctx)
 
-		defer func() {
-			if r := recover(); r == nil {
-				t.Errorf("The code did not panic")
+		start := time.Now()
+		router.ServeHTTP(rr, r)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("expected handler to return when context is cancelled, took %s", elapsed)
+		}
+
+		select {
+		case name := <-leaks:
+			if name != "LeakyStream" {
+				t.Errorf("unexpected leak reported, got %q", name)
 			}
+		case <-time.After(time.Second):
+			t.Error("expected leak to be reported")
+		}
+	})
+
+	t.Run("shutdown finishes open streams", func(t *testing.T) {
+		t.Parallel()
+		router := NewRouter()
+		svc := testService{}
+		router.Register(Stream(svc.EmptyStreamForSixSeconds))
+
+		finished := make(chan struct{})
+		go func() {
+			defer close(finished)
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/EmptyStreamForSixSeconds", nil))
 		}()
 
-		router.ServeHTTP(rr, r)
+		// wait for stream to be opened
+		time.Sleep(100 * time.Millisecond)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := router.Shutdown(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		select {
+		case <-finished:
+		case <-time.After(time.Second):
+			t.Error("expected stream to be finished")
+		}
+
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest("GET", "/EmptyStreamForSixSeconds", nil))
+		if rr.Code != http.StatusServiceUnavailable {
+			t.Errorf("expected status code %d, got %d", http.StatusServiceUnavailable, rr.Code)
+		}
+
+		other := NewRouter()
+		other.Register(Stream(svc.StreamOneEvent))
+
+		rr = httptest.NewRecorder()
+		other.ServeHTTP(rr, httptest.NewRequest("GET", "/StreamOneEvent?value=test", nil))
+		if rr.Code != http.StatusOK {
+			t.Errorf("expected other router to serve streams, got %d", rr.Code)
+		}
 	})
 
 	t.Run("receive one event", func(t *testing.T) {
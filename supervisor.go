@@ -0,0 +1,104 @@
+package ferry
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// LeakPolicy is called when Stream function does not close its channel in time after the stream has finished.
+type LeakPolicy func(info StreamInfo)
+
+// LogLeaks logs leaking streams with standard logger. This is the default LeakPolicy.
+var LogLeaks LeakPolicy = func(info StreamInfo) {
+	log.Printf("ferry: %q stream channel is not closed after stream has finished", info.Name)
+}
+
+// PanicOnLeak panics when stream leaks. Panic happens in background goroutine, so it crashes the program.
+var PanicOnLeak LeakPolicy = func(info StreamInfo) {
+	panic("ferry: " + info.Name + " stream channel is not closed after stream has finished")
+}
+
+// supervisor tracks open streams of Router and signals them to finish on shutdown.
+type supervisor struct {
+	mu     sync.Mutex
+	closed bool
+	done   chan struct{}
+	open   sync.WaitGroup
+}
+
+func newSupervisor() *supervisor {
+	return &supervisor{done: make(chan struct{})}
+}
+
+// start registers new stream. It returns false if supervisor is shut down.
+func (s *supervisor) start() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.open.Add(1)
+	return true
+}
+
+// finish unregisters finished stream.
+func (s *supervisor) finish() {
+	s.open.Done()
+}
+
+func (s *supervisor) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+	s.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		s.open.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// errShuttingDown is returned to clients opening streams after Shutdown is called.
+var errShuttingDown = Unavailable("server is shutting down", 0)
+
+// supervise receives remaining events of finished stream in background, so stream function is not blocked.
+// If channel is not closed within timeout, leak is reported to policy and channel is drained until it is closed.
+func supervise(events reflect.Value, timeout time.Duration, policy LeakPolicy, info StreamInfo) {
+	go func() {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		cases := []reflect.SelectCase{
+			{Dir: reflect.SelectRecv, Chan: events},
+			{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(timer.C)},
+		}
+
+		for {
+			chosen, _, ok := reflect.Select(cases)
+			if chosen == 0 && !ok {
+				return
+			}
+
+			if chosen == 1 {
+				policy(info)
+				// stop waiting for timer, keep receiving events until channel is closed
+				cases = cases[:1]
+			}
+		}
+	}()
+}
@@ -0,0 +1,346 @@
+package ferry
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// TypeScript walks chi.Router routing tree and creates http.HandlerFunc
+// that will return TypeScript module with interfaces of request, response and event types
+// and client which calls Procedure handlers with fetch and subscribes to Stream handlers with EventSource.
+func TypeScript(router chi.Router) http.HandlerFunc {
+	generator := newTypeScriptGenerator()
+	methods := make([]string, 0)
+	names := make(map[string]bool)
+
+	chi.Walk(router, func(method, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+		var m meta
+		switch h := handler.(type) {
+		case *procedureHandler:
+			m = h.meta
+		case *streamHandler:
+			m = h.meta
+		default:
+			return nil
+		}
+
+		if method != m.method {
+			// stream registered with additional methods is called by its own method only
+			return nil
+		}
+
+		name := lowerFirst(m.name)
+		if names[name] {
+			// fall back to full path when method names collide
+			name = lowerFirst(pathName(route))
+		}
+		names[name] = true
+
+		if _, ok := handler.(*streamHandler); ok {
+			methods = append(methods, generator.stream(name, route, m))
+		} else {
+			methods = append(methods, generator.procedure(name, route, m))
+		}
+
+		return nil
+	})
+
+	var source strings.Builder
+	source.WriteString("// Code generated by ferry. DO NOT EDIT.\n\n")
+	source.WriteString(generator.declarations())
+	source.WriteString(typeScriptRuntime)
+	source.WriteString("export function createClient(baseURL: string = \"\") {\n  return {\n")
+	source.WriteString(strings.Join(methods, ""))
+	source.WriteString("  };\n}\n")
+
+	module := source.String()
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, module)
+	}
+}
+
+// typeScriptGenerator maps Go types to TypeScript types.
+// Named struct types are declared as interfaces.
+type typeScriptGenerator struct {
+	decls map[string]string
+	names *typeNames
+}
+
+func newTypeScriptGenerator() *typeScriptGenerator {
+	return &typeScriptGenerator{decls: make(map[string]string), names: newTypeNames()}
+}
+
+// procedure returns client method which calls Procedure with fetch.
+// Procedures registered with methods without body receive request fields with `query` tag as URL parameters.
+func (g *typeScriptGenerator) procedure(name, route string, m meta) string {
+	request, response := g.typeOf(m.request), g.typeOf(m.response)
+
+	if !hasBody(m.method) {
+		return fmt.Sprintf("    %s: (request: %s, init?: RequestInit): Promise<%s> =>\n      call<%s>(%q, baseURL + %q + query([%s]), undefined, init),\n",
+			name, request, response, response, m.method, route, queryParams(m))
+	}
+
+	return fmt.Sprintf("    %s: (request: %s, init?: RequestInit): Promise<%s> =>\n      call<%s>(%q, baseURL + %q, request, init),\n",
+		name, request, response, response, m.method, route)
+}
+
+// stream returns client method which subscribes to Stream with EventSource.
+// Request fields with `query` tag are sent as URL parameters. Types of events overridden by server are not known
+// upfront, so clients have to list them in eventTypes argument.
+func (g *typeScriptGenerator) stream(name, route string, m meta) string {
+	request, payload := g.typeOf(m.request), g.typeOf(m.response)
+
+	events := make([]string, 0)
+	for _, event := range eventNames(m.response) {
+		events = append(events, fmt.Sprintf("%q", event))
+	}
+
+	return fmt.Sprintf("    %s: (request: %s, onMessage: (event: StreamEvent<%s>) => void, onError?: (error: FerryError | Event) => void, eventTypes?: string[]): EventSource =>\n      subscribe<%s>(baseURL + %q + query([%s]), [%s], onMessage, onError, eventTypes),\n",
+		name, request, payload, payload, route, queryParams(m), strings.Join(events, ", "))
+}
+
+// queryParams returns TypeScript array of key and value pairs of request fields with `query` tag.
+func queryParams(m meta) string {
+	params := make([]string, 0)
+	for _, field := range bindingFields(m.request, "query") {
+		if len(field.path) == 0 {
+			// property is not part of JSON representation of request
+			continue
+		}
+
+		accessor := "request"
+		for i, name := range field.path {
+			if i > 0 {
+				accessor += "?."
+			}
+			accessor += fmt.Sprintf("[%q]", name)
+		}
+
+		params = append(params, fmt.Sprintf("[%q, %s]", field.key, accessor))
+	}
+
+	return strings.Join(params, ", ")
+}
+
+// declarations returns interfaces of all named types sorted by name.
+func (g *typeScriptGenerator) declarations() string {
+	names := make([]string, 0, len(g.decls))
+	for name := range g.decls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&out, "export interface %s %s\n\n", name, g.decls[name])
+	}
+
+	return out.String()
+}
+
+// typeOf returns TypeScript type of JSON representation of the given type.
+func (g *typeScriptGenerator) typeOf(t reflect.Type) string {
+	if t == timeType {
+		return "string"
+	}
+	if isBinary(t) {
+		return "Blob"
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return g.typeOf(t.Elem()) + " | null"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json encodes byte slices as base64 strings
+			return "string"
+		}
+		elem := g.typeOf(t.Elem())
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		return elem + "[]"
+	case reflect.Map:
+		return "Record<string, " + g.typeOf(t.Elem()) + ">"
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, "")
+		}
+		name := g.names.of(t)
+		if _, ok := g.decls[name]; !ok {
+			// placeholder prevents infinite recursion on self-referencing types
+			g.decls[name] = ""
+			g.decls[name] = g.object(t, "")
+		}
+		return name
+	case reflect.Interface:
+		types := variantsOf(t)
+		if len(types) == 0 {
+			return "unknown"
+		}
+		union := make([]string, 0, len(types))
+		for _, variant := range types {
+			union = append(union, g.typeOf(variant))
+		}
+		return strings.Join(union, " | ")
+	default:
+		return "unknown"
+	}
+}
+
+// object returns TypeScript object type of struct fields. Fields tagged with omitempty are optional.
+func (g *typeScriptGenerator) object(t reflect.Type, indent string) string {
+	fields := jsonFields(t)
+	if len(fields) == 0 {
+		return "{}"
+	}
+
+	var out strings.Builder
+	out.WriteString("{\n")
+	for _, field := range fields {
+		optional := ""
+		if field.OmitEmpty {
+			optional = "?"
+		}
+
+		fieldType := g.typeOf(field.Type)
+		if field.Quoted {
+			fieldType = "string"
+		}
+
+		fmt.Fprintf(&out, "%s  %s%s: %s;\n", indent, quoteProperty(field.Name), optional, fieldType)
+	}
+	out.WriteString(indent + "}")
+
+	return out.String()
+}
+
+// quoteProperty quotes property names which are not valid identifiers.
+func quoteProperty(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || r == '$' || (i > 0 && unicode.IsDigit(r))) {
+			return fmt.Sprintf("%q", name)
+		}
+	}
+
+	return name
+}
+
+// pathName joins path segments into camel case name.
+func pathName(route string) string {
+	var name strings.Builder
+	for _, segment := range strings.Split(strings.Trim(route, "/"), "/") {
+		if segment != "" {
+			name.WriteString(strings.ToUpper(segment[:1]) + segment[1:])
+		}
+	}
+
+	return name.String()
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+const typeScriptRuntime = `export interface StreamEvent<P> {
+  id: string;
+  type: string;
+  payload: P;
+}
+
+export class FerryError extends Error {
+  constructor(public status: number, message: string, public code?: string, public details?: unknown[], public fields?: FieldError[]) {
+    super(message);
+  }
+}
+
+export interface FieldError {
+  field: string;
+  rule: string;
+  message: string;
+}
+
+async function call<Res>(method: string, url: string, request: unknown, init?: RequestInit): Promise<Res> {
+  // requests of methods without body are sent as query parameters
+  const response = await fetch(url, request === undefined ? { ...init, method } : {
+    ...init,
+    method,
+    headers: { ...init?.headers, "Content-Type": "application/json" },
+    body: JSON.stringify(request),
+  });
+
+  if (response.ok && !response.headers.get("Content-Type")?.includes("json")) {
+    // binary responses are returned as they are
+    return (await response.blob()) as Res;
+  }
+
+  const body = await response.json().catch(() => ({}));
+  if (!response.ok) {
+    throw new FerryError(response.status, body.error ?? response.statusText, body.code, body.details, body.fields);
+  }
+
+  return body as Res;
+}
+
+function query(params: [string, unknown][]): string {
+  const search = new URLSearchParams();
+  for (const [key, value] of params) {
+    for (const item of Array.isArray(value) ? value : [value]) {
+      if (item !== undefined && item !== null) {
+        search.append(key, String(item));
+      }
+    }
+  }
+
+  const encoded = search.toString();
+  return encoded ? "?" + encoded : "";
+}
+
+function subscribe<P>(url: string, eventNames: string[], onMessage: (event: StreamEvent<P>) => void, onError?: (error: FerryError | Event) => void, eventTypes: string[] = []): EventSource {
+  const source = new EventSource(url);
+  const dispatch = (event: Event) => {
+    const message = event as MessageEvent;
+    onMessage({ id: message.lastEventId, type: message.type, payload: JSON.parse(message.data) as P });
+  };
+  // EventSource dispatches events by name only: events overriding their type are received when the type is listed
+  // in eventTypes, unnamed events fall back to "message"
+  const names = eventNames.concat(eventTypes, "message");
+  names.filter((name, i) => names.indexOf(name) === i).forEach((name) => source.addEventListener(name, dispatch));
+  source.addEventListener("error", (event) => {
+    const message = event as MessageEvent;
+    if (typeof message.data !== "string") {
+      // connection error, EventSource reconnects automatically
+      onError?.(event);
+      return;
+    }
+
+    // stream was terminated by server
+    source.close();
+    const body = JSON.parse(message.data);
+    onError?.(new FerryError(0, body.error, body.code, body.details, body.fields));
+  });
+
+  return source;
+}
+
+`
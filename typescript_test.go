@@ -0,0 +1,59 @@
+package ferry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type typeScriptResponse struct {
+	Payload  testPayload            `json:"payload"`
+	Children []*typeScriptResponse  `json:"children,omitempty"`
+	Labels   map[string]testPayload `json:"labels"`
+	Data     []byte                 `json:"data"`
+	Internal string                 `json:"-"`
+}
+
+func (t testService) TestTypeScript(ctx context.Context, r *jsonRequest) (*typeScriptResponse, error) {
+	return &typeScriptResponse{}, nil
+}
+
+func TestTypeScript(t *testing.T) {
+	svc := testService{}
+	service := NewRouter()
+	service.Register(
+		Procedure(svc.TestTypeScript),
+		Stream(svc.StreamOneEvent),
+		Procedure(svc.FindValue, Method(http.MethodGet)),
+	)
+
+	router := chi.NewRouter()
+	router.Mount("/api/v1/TestService", service)
+
+	rr := httptest.NewRecorder()
+	TypeScript(router)(rr, httptest.NewRequest(http.MethodGet, "/client.ts", nil))
+	module := rr.Body.String()
+
+	expected := []string{
+		"export interface jsonRequest {\n  value: string;\n}",
+		"export interface testPayload {\n  value: string;\n}",
+		"export interface typeScriptResponse {\n  payload: testPayload;\n  children?: (typeScriptResponse | null)[];\n  labels: Record<string, testPayload>;\n  data: string;\n}",
+		"export interface queryRequest {\n  Value: string;\n}",
+		`testTypeScript: (request: jsonRequest, init?: RequestInit): Promise<typeScriptResponse> =>
+      call<typeScriptResponse>("POST", baseURL + "/api/v1/TestService/TestTypeScript", request, init),`,
+		`findValue: (request: queryRequest, init?: RequestInit): Promise<testPayload> =>
+      call<testPayload>("GET", baseURL + "/api/v1/TestService/FindValue" + query([["value", request["Value"]]]), undefined, init),`,
+		`streamOneEvent: (request: queryRequest, onMessage: (event: StreamEvent<testPayload>) => void, onError?: (error: FerryError | Event) => void, eventTypes?: string[]): EventSource =>
+      subscribe<testPayload>(baseURL + "/api/v1/TestService/StreamOneEvent" + query([["value", request["Value"]]]), ["testPayload"], onMessage, onError, eventTypes),`,
+	}
+
+	for _, fragment := range expected {
+		if !strings.Contains(module, fragment) {
+			t.Errorf("expected module to contain:\n%s\ngot:\n%s", fragment, module)
+		}
+	}
+}
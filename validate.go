@@ -0,0 +1,399 @@
+package ferry
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+	uuidPattern  = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// ValidationError is returned when decoded request does not satisfy `validate` tag rules.
+// DefaultErrorHandler responds with 422 Unprocessable Entity and lists all invalid fields.
+type ValidationError struct {
+	Message string       `json:"error"`
+	Fields  []FieldError `json:"fields"`
+}
+
+func (e ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i := range e.Fields {
+		messages[i] = e.Fields[i].Field + " " + e.Fields[i].Message
+	}
+
+	return e.Message + ": " + strings.Join(messages, ", ")
+}
+
+// FieldError describes single violated rule. Field is the path of the field built from json or query names.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// rule is a single parsed `validate` tag entry such as "min=3".
+type rule struct {
+	name  string
+	param string
+}
+
+// parseRules splits `validate` tag into rules.
+// Pattern can contain commas, so it consumes the rest of the tag and must be the last rule.
+func parseRules(tag string) []rule {
+	rules := make([]rule, 0)
+
+	for tag != "" {
+		var entry string
+		if strings.HasPrefix(tag, "pattern=") {
+			entry, tag = tag, ""
+		} else {
+			entry, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, param, _ := strings.Cut(strings.TrimSpace(entry), "=")
+		if name != "" {
+			rules = append(rules, rule{name: name, param: param})
+		}
+	}
+
+	return rules
+}
+
+// validator validates values of single type.
+type validator struct {
+	checks []check
+	// elem validates elements of slices, arrays and maps.
+	elem *validator
+	// object validates fields of structs.
+	object *structValidator
+}
+
+// structValidator is shared by all validators of the same struct type, so recursive types can be validated.
+type structValidator struct {
+	fields   []fieldValidator
+	compiled bool
+}
+
+type fieldValidator struct {
+	index int
+	name  string
+	*validator
+}
+
+// check is a compiled rule.
+type check struct {
+	rule    string
+	message string
+	valid   func(v reflect.Value) bool
+}
+
+type validatorKey struct {
+	t   reflect.Type
+	tag string
+}
+
+// validators caches compiled validators by type and naming tag.
+var validators sync.Map
+
+// validate checks v against `validate` tags of its fields.
+// Tag is "json" or "query" and is used to name fields in errors.
+func validate(v interface{}, tag string) error {
+	vd, err := compileValidator(reflect.TypeOf(v), tag)
+	if err != nil {
+		return fmt.Errorf("compile validator: %w", err)
+	}
+
+	violations := vd.validate(reflect.ValueOf(v), "", nil)
+	if len(violations) == 0 {
+		return nil
+	}
+
+	return ValidationError{Message: "validation failed", Fields: violations}
+}
+
+// compileValidator returns cached validator of the struct type or compiles a new one.
+func compileValidator(t reflect.Type, tag string) (*validator, error) {
+	key := validatorKey{t: t, tag: tag}
+	if vd, ok := validators.Load(key); ok {
+		return vd.(*validator), nil
+	}
+
+	vd, err := newValidator(t, nil, tag, make(map[reflect.Type]*structValidator))
+	if err != nil {
+		return nil, err
+	}
+
+	validators.Store(key, vd)
+
+	return vd, nil
+}
+
+// isValidated reports whether values of type t have any rules to check.
+func isValidated(t reflect.Type) bool {
+	vd, err := compileValidator(reflect.PtrTo(t), "json")
+	return err == nil && !vd.empty()
+}
+
+// newValidator compiles rules for values of type t. Structs are compiled recursively,
+// objects holds validators of struct types which were already seen.
+func newValidator(t reflect.Type, rules []rule, tag string, objects map[reflect.Type]*structValidator) (*validator, error) {
+	vd := new(validator)
+
+	for i, r := range rules {
+		if r.name == "dive" {
+			elem := indirectType(t)
+			switch elem.Kind() {
+			case reflect.Slice, reflect.Array, reflect.Map:
+			default:
+				return nil, fmt.Errorf("dive can not be used on %s", t)
+			}
+
+			var err error
+			if vd.elem, err = newValidator(elem.Elem(), rules[i+1:], tag, objects); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		c, err := newCheck(t, r)
+		if err != nil {
+			return nil, err
+		}
+		vd.checks = append(vd.checks, c)
+	}
+
+	base := indirectType(t)
+	switch {
+	case base.Kind() == reflect.Struct && base != timeType:
+		if object, ok := objects[base]; ok {
+			vd.object = object
+			break
+		}
+
+		vd.object = new(structValidator)
+		objects[base] = vd.object
+		for i := 0; i < base.NumField(); i++ {
+			field := base.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fv, err := newValidator(field.Type, parseRules(field.Tag.Get("validate")), tag, objects)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", base.Name(), field.Name, err)
+			}
+
+			if fv.empty() {
+				continue
+			}
+
+			vd.object.fields = append(vd.object.fields, fieldValidator{index: i, name: fieldName(field, tag), validator: fv})
+		}
+		vd.object.compiled = true
+	case vd.elem == nil && (base.Kind() == reflect.Slice || base.Kind() == reflect.Array || base.Kind() == reflect.Map):
+		// nested structs are validated without explicit dive
+		elem, err := newValidator(base.Elem(), nil, tag, objects)
+		if err != nil {
+			return nil, err
+		}
+		if !elem.empty() {
+			vd.elem = elem
+		}
+	}
+
+	return vd, nil
+}
+
+// empty reports whether validator has nothing to check.
+// Struct which is still being compiled is not empty, because it references itself.
+func (vd *validator) empty() bool {
+	return len(vd.checks) == 0 && vd.elem == nil && (vd.object == nil || (vd.object.compiled && len(vd.object.fields) == 0))
+}
+
+// validate returns violations of value v found at given path.
+func (vd *validator) validate(v reflect.Value, path string, violations []FieldError) []FieldError {
+	for _, c := range vd.checks {
+		if c.rule != "required" && v.Kind() == reflect.Ptr && v.IsNil() {
+			// optional values are validated only when present
+			continue
+		}
+
+		if !c.valid(v) {
+			violations = append(violations, FieldError{Field: path, Rule: c.rule, Message: c.message})
+		}
+	}
+
+	v = reflect.Indirect(v)
+	if !v.IsValid() {
+		return violations
+	}
+
+	if vd.object != nil {
+		for _, f := range vd.object.fields {
+			violations = f.validate(v.Field(f.index), joinPath(path, f.name), violations)
+		}
+	}
+
+	if vd.elem != nil {
+		switch v.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < v.Len(); i++ {
+				violations = vd.elem.validate(v.Index(i), fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				violations = vd.elem.validate(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key()), violations)
+			}
+		}
+	}
+
+	return violations
+}
+
+// newCheck compiles rule for values of type t.
+func newCheck(t reflect.Type, r rule) (check, error) {
+	base := indirectType(t)
+	kind := base.Kind()
+
+	switch r.name {
+	case "required":
+		return check{rule: r.name, message: "is required", valid: func(v reflect.Value) bool {
+			if v.Kind() == reflect.Ptr {
+				return !v.IsNil()
+			}
+			return !v.IsZero()
+		}}, nil
+	case "min", "max":
+		limit, err := strconv.ParseFloat(r.param, 64)
+		if err != nil {
+			return check{}, fmt.Errorf("invalid %s parameter %q", r.name, r.param)
+		}
+
+		atLeast := r.name == "min"
+		compare := func(n float64) bool {
+			if atLeast {
+				return n >= limit
+			}
+			return n <= limit
+		}
+		bound := "at most"
+		if atLeast {
+			bound = "at least"
+		}
+
+		switch {
+		case kind == reflect.String:
+			return check{rule: r.name, message: fmt.Sprintf("must be %s %s characters long", bound, r.param), valid: func(v reflect.Value) bool {
+				return compare(float64(utf8.RuneCountInString(reflect.Indirect(v).String())))
+			}}, nil
+		case kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map:
+			return check{rule: r.name, message: fmt.Sprintf("must contain %s %s items", bound, r.param), valid: func(v reflect.Value) bool {
+				return compare(float64(reflect.Indirect(v).Len()))
+			}}, nil
+		case isNumber(kind):
+			relation := "less than or equal to"
+			if atLeast {
+				relation = "greater than or equal to"
+			}
+			return check{rule: r.name, message: fmt.Sprintf("must be %s %s", relation, r.param), valid: func(v reflect.Value) bool {
+				return compare(numberValue(reflect.Indirect(v)))
+			}}, nil
+		}
+	case "pattern":
+		if kind != reflect.String {
+			break
+		}
+		pattern, err := regexp.Compile(r.param)
+		if err != nil {
+			return check{}, fmt.Errorf("invalid pattern %q: %w", r.param, err)
+		}
+		return check{rule: r.name, message: fmt.Sprintf("must match pattern %q", r.param), valid: func(v reflect.Value) bool {
+			return pattern.MatchString(reflect.Indirect(v).String())
+		}}, nil
+	case "oneof":
+		if kind != reflect.String && !isNumber(kind) {
+			break
+		}
+		options := strings.Fields(r.param)
+		return check{rule: r.name, message: fmt.Sprintf("must be one of [%s]", strings.Join(options, ", ")), valid: func(v reflect.Value) bool {
+			value := fmt.Sprint(reflect.Indirect(v).Interface())
+			for _, option := range options {
+				if value == option {
+					return true
+				}
+			}
+			return false
+		}}, nil
+	case "email", "uuid":
+		if kind != reflect.String {
+			break
+		}
+		pattern, message := emailPattern, "must be a valid email address"
+		if r.name == "uuid" {
+			pattern, message = uuidPattern, "must be a valid UUID"
+		}
+		return check{rule: r.name, message: message, valid: func(v reflect.Value) bool {
+			return pattern.MatchString(reflect.Indirect(v).String())
+		}}, nil
+	default:
+		return check{}, fmt.Errorf("unknown validation rule %q", r.name)
+	}
+
+	return check{}, fmt.Errorf("validation rule %q can not be used on %s", r.name, t)
+}
+
+// fieldName returns name of the field used in JSON or query, depending on tag.
+func fieldName(field reflect.StructField, tag string) string {
+	name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func isNumber(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func numberValue(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
@@ -0,0 +1,198 @@
+package ferry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+type validatedRequest struct {
+	Name     string            `json:"name" validate:"required,min=3,max=10"`
+	Email    *string           `json:"email,omitempty" validate:"email"`
+	Age      int               `json:"age" validate:"min=18,max=130"`
+	Role     string            `json:"role" validate:"oneof=admin user"`
+	ID       string            `json:"id" validate:"uuid"`
+	Code     string            `json:"code" validate:"pattern=^[A-Z]{2,3}$"`
+	Tags     []string          `json:"tags" validate:"max=2,dive,min=2"`
+	Address  validatedAddress  `json:"address"`
+	Contacts []validatedPerson `json:"contacts"`
+}
+
+type validatedAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type validatedPerson struct {
+	Name   string           `json:"name" validate:"required"`
+	Friend *validatedPerson `json:"friend"`
+}
+
+type validatedQuery struct {
+	Name string `query:"name" validate:"required"`
+}
+
+func validRequest() validatedRequest {
+	return validatedRequest{
+		Name:    "John",
+		Age:     30,
+		Role:    "admin",
+		ID:      "123e4567-e89b-12d3-a456-426614174000",
+		Code:    "LT",
+		Tags:    []string{"go"},
+		Address: validatedAddress{City: "Vilnius"},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	invalidEmail := "invalid"
+
+	testCases := []struct {
+		name     string
+		modify   func(r *validatedRequest)
+		expected []FieldError
+	}{
+		{
+			name:   "valid",
+			modify: func(r *validatedRequest) {},
+		},
+		{
+			name:   "required and length",
+			modify: func(r *validatedRequest) { r.Name = "" },
+			expected: []FieldError{
+				{Field: "name", Rule: "required", Message: "is required"},
+				{Field: "name", Rule: "min", Message: "must be at least 3 characters long"},
+			},
+		},
+		{
+			name:     "optional pointer",
+			modify:   func(r *validatedRequest) { r.Email = &invalidEmail },
+			expected: []FieldError{{Field: "email", Rule: "email", Message: "must be a valid email address"}},
+		},
+		{
+			name:     "numeric range",
+			modify:   func(r *validatedRequest) { r.Age = 17 },
+			expected: []FieldError{{Field: "age", Rule: "min", Message: "must be greater than or equal to 18"}},
+		},
+		{
+			name:   "enum, format and pattern",
+			modify: func(r *validatedRequest) { r.Role, r.ID, r.Code = "guest", "123", "lt" },
+			expected: []FieldError{
+				{Field: "role", Rule: "oneof", Message: "must be one of [admin, user]"},
+				{Field: "id", Rule: "uuid", Message: "must be a valid UUID"},
+				{Field: "code", Rule: "pattern", Message: `must match pattern "^[A-Z]{2,3}$"`},
+			},
+		},
+		{
+			name:   "dive into slice",
+			modify: func(r *validatedRequest) { r.Tags = []string{"go", "a", "b"} },
+			expected: []FieldError{
+				{Field: "tags", Rule: "max", Message: "must contain at most 2 items"},
+				{Field: "tags[1]", Rule: "min", Message: "must be at least 2 characters long"},
+				{Field: "tags[2]", Rule: "min", Message: "must be at least 2 characters long"},
+			},
+		},
+		{
+			name: "nested structs",
+			modify: func(r *validatedRequest) {
+				r.Address.City = ""
+				r.Contacts = []validatedPerson{{Name: "Jane", Friend: &validatedPerson{}}}
+			},
+			expected: []FieldError{
+				{Field: "address.city", Rule: "required", Message: "is required"},
+				{Field: "contacts[0].friend.name", Rule: "required", Message: "is required"},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			r := validRequest()
+			testCase.modify(&r)
+
+			err := validate(&r, "json")
+			if testCase.expected == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			validationErr, ok := err.(ValidationError)
+			if !ok {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+
+			if !reflect.DeepEqual(validationErr.Fields, testCase.expected) {
+				t.Errorf("unexpected fields, got %+v", validationErr.Fields)
+			}
+		})
+	}
+
+	t.Run("names query fields by query tag", func(t *testing.T) {
+		err := validate(&validatedQuery{}, "query")
+		expected := []FieldError{{Field: "name", Rule: "required", Message: "is required"}}
+		if validationErr, ok := err.(ValidationError); !ok || !reflect.DeepEqual(validationErr.Fields, expected) {
+			t.Errorf("unexpected error, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid rules", func(t *testing.T) {
+		invalid := []interface{}{
+			&struct {
+				Value int `validate:"pattern=^a$"`
+			}{},
+			&struct {
+				Value string `validate:"min=abc"`
+			}{},
+			&struct {
+				Value string `validate:"unknown"`
+			}{},
+			&struct {
+				Value string `validate:"dive,required"`
+			}{},
+		}
+
+		for _, v := range invalid {
+			if _, err := compileValidator(reflect.TypeOf(v), "json"); err == nil {
+				t.Errorf("expected error for %T", v)
+			}
+		}
+	})
+}
+
+func (t testService) TestValidatedProcedure(ctx context.Context, r *validatedRequest) (*testPayload, error) {
+	return &testPayload{Value: r.Name}, nil
+}
+
+func TestValidatedProcedure(t *testing.T) {
+	router := NewRouter()
+	svc := testService{}
+	router.Register(Procedure(svc.TestValidatedProcedure))
+
+	payload, err := json.Marshal(validatedRequest{Name: "Jo"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	rr := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/TestValidatedProcedure", bytes.NewReader(payload))
+	r.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rr, r)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unexpected response code, got %d", rr.Code)
+	}
+
+	var body ValidationError
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if body.Message != "validation failed" || len(body.Fields) == 0 || body.Fields[0].Field != "name" {
+		t.Errorf("unexpected response, got %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,171 @@
+package ferry
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// variants maps interface types to types of values registered with RegisterVariants.
+var variants sync.Map
+
+// RegisterVariants registers types which implement interface I, so Stream of I can emit events of several types.
+// Each event is named after the type of its payload. Variants are listed in service discovery, OpenAPI
+// and TypeScript client, and are used by Subscribe to decode events. Variants must be registered before
+// handlers are created, e.g. in init function.
+func RegisterVariants[I any](values ...I) {
+	iface := reflect.TypeOf(new(I)).Elem()
+	if iface.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("can not register variants of %s: type is not an interface", iface))
+	}
+
+	types := variantsOf(iface)
+	for i := range values {
+		t := reflect.TypeOf(values[i])
+		if t == nil {
+			panic(fmt.Sprintf("can not register variant of %s: value is nil", iface))
+		}
+
+		for _, registered := range types {
+			if eventName(registered) == eventName(t) {
+				panic(fmt.Sprintf("can not register variant %s of %s: variant with the same name is already registered", t, iface))
+			}
+		}
+
+		types = append(types, t)
+	}
+
+	variants.Store(iface, types)
+}
+
+// variantsOf returns registered variants of interface type.
+func variantsOf(t reflect.Type) []reflect.Type {
+	types, ok := variants.Load(t)
+	if !ok {
+		return nil
+	}
+
+	return types.([]reflect.Type)
+}
+
+// variantByName returns registered variant of interface type which events are named by given name.
+func variantByName(t reflect.Type, name string) (reflect.Type, bool) {
+	for _, variant := range variantsOf(t) {
+		if eventName(variant) == name {
+			return variant, true
+		}
+	}
+
+	return nil, false
+}
+
+// eventName returns name of events which carry payload of the given type.
+// Events of anonymous types are named "message", which is the default event type of Server-Sent Events.
+func eventName(t reflect.Type) string {
+	if name := typeName(t); name != "" {
+		return name
+	}
+
+	return "message"
+}
+
+// eventNames returns names of events carrying payload of the given type.
+// Interfaces with registered variants have event per variant.
+func eventNames(t reflect.Type) []string {
+	if t.Kind() == reflect.Interface {
+		if types := variantsOf(t); len(types) > 0 {
+			names := make([]string, 0, len(types))
+			for _, variant := range types {
+				names = append(names, eventName(variant))
+			}
+			return names
+		}
+	}
+
+	return []string{eventName(t)}
+}
+
+// dynamicEventName returns event name of the payload held by pointer to interface.
+func dynamicEventName(payload interface{}) string {
+	v := reflect.ValueOf(payload)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "message"
+		}
+		v = v.Elem()
+	}
+
+	return eventName(v.Type())
+}
+
+var (
+	// qualifier matches package path in type names of generic type arguments.
+	qualifier = regexp.MustCompile(`[\w.\-/]*\.`)
+	// identifier matches parts of type name which can be used in identifiers.
+	identifier = regexp.MustCompile(`[A-Za-z0-9_]+`)
+)
+
+// typeName returns name of the type which can be used as identifier.
+// Generic type names are joined with type argument names without package paths, e.g. Page[pkg.User] becomes PageUser.
+// Pointer types are named after their element types, anonymous types have no name.
+func typeName(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	name := t.Name()
+	if !strings.Contains(name, "[") {
+		return name
+	}
+
+	parts := identifier.FindAllString(qualifier.ReplaceAllString(name, ""), -1)
+	for i := 1; i < len(parts); i++ {
+		parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+	}
+
+	return strings.Join(parts, "")
+}
+
+// typeNames assigns unique names to named types described in one document. Type which has the same name as
+// already described type of another package is qualified with its package name, e.g. v2.User becomes V2User.
+type typeNames struct {
+	names map[reflect.Type]string
+	types map[string]reflect.Type
+}
+
+func newTypeNames() *typeNames {
+	return &typeNames{names: make(map[reflect.Type]string), types: make(map[string]reflect.Type)}
+}
+
+// of returns unique name of the type.
+func (n *typeNames) of(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if name, ok := n.names[t]; ok {
+		return name
+	}
+
+	name := typeName(t)
+	if _, taken := n.types[name]; taken {
+		if pkg := strings.Join(identifier.FindAllString(path.Base(t.PkgPath()), -1), ""); pkg != "" {
+			name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+		}
+	}
+	for i, base := 2, name; ; i++ {
+		if _, taken := n.types[name]; !taken {
+			break
+		}
+		// packages of the same name are told apart by number
+		name = base + strconv.Itoa(i)
+	}
+
+	n.names[t], n.types[name] = name, t
+
+	return name
+}
@@ -0,0 +1,200 @@
+package ferry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type chatEvent interface{ chatEvent() }
+
+type userJoined struct {
+	Name string `json:"name"`
+}
+
+type messagePosted struct {
+	Text string `json:"text"`
+}
+
+func (userJoined) chatEvent()     {}
+func (*messagePosted) chatEvent() {}
+
+type page[T any] struct {
+	Items []T `json:"items"`
+}
+
+func init() {
+	RegisterVariants[chatEvent](userJoined{}, &messagePosted{})
+}
+
+func (s testService) ChatStream(ctx context.Context, _ *empty) (<-chan Event[chatEvent], error) {
+	c := make(chan Event[chatEvent])
+
+	go func() {
+		defer close(c)
+
+		events := []chatEvent{userJoined{Name: "John"}, &messagePosted{Text: "hello"}}
+		for i := range events {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- Event[chatEvent]{ID: "1", Payload: &events[i]}:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+func (s testService) PageStream(ctx context.Context, _ *empty) (<-chan Event[page[testPayload]], error) {
+	c := make(chan Event[page[testPayload]])
+
+	go func() {
+		defer close(c)
+
+		for _, eventType := range []string{"", "custom"} {
+			select {
+			case <-ctx.Done():
+				return
+			case c <- Event[page[testPayload]]{ID: "1", Type: eventType, Payload: &page[testPayload]{}}:
+			}
+		}
+	}()
+
+	return c, nil
+}
+
+func TestTypeName(t *testing.T) {
+	tests := []struct {
+		typ      reflect.Type
+		expected string
+	}{
+		{typ: reflect.TypeOf(testPayload{}), expected: "testPayload"},
+		{typ: reflect.TypeOf(&testPayload{}), expected: "testPayload"},
+		{typ: reflect.TypeOf(page[testPayload]{}), expected: "pageTestPayload"},
+		{typ: reflect.TypeOf(page[map[string]*time.Time]{}), expected: "pageMapStringTime"},
+		{typ: reflect.TypeOf(struct{}{}), expected: ""},
+	}
+
+	for _, tt := range tests {
+		if name := typeName(tt.typ); name != tt.expected {
+			t.Errorf("expected %q, got %q", tt.expected, name)
+		}
+	}
+}
+
+func TestMultiTypeStream(t *testing.T) {
+	svc := testService{}
+	router := NewRouter()
+	if err := router.Register(Stream(svc.ChatStream), Stream(svc.PageStream)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	t.Run("names events after payload types", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/ChatStream", nil))
+
+		expected := "event: keep-alive\n\n" +
+			"id: 1\nevent: userJoined\ndata: {\"name\":\"John\"}\n\n" +
+			"id: 1\nevent: messagePosted\ndata: {\"text\":\"hello\"}\n\n"
+
+		if body := rr.Body.String(); body != expected {
+			t.Errorf("unexpected response, got %q", body)
+		}
+	})
+
+	t.Run("names events of generic types and overrides names", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/PageStream", nil))
+
+		expected := "event: keep-alive\n\n" +
+			"id: 1\nevent: pageTestPayload\ndata: {\"items\":null}\n\n" +
+			"id: 1\nevent: custom\ndata: {\"items\":null}\n\n"
+
+		if body := rr.Body.String(); body != expected {
+			t.Errorf("unexpected response, got %q", body)
+		}
+	})
+
+	t.Run("decodes variants", func(t *testing.T) {
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		events, err := Subscribe[empty, chatEvent](ctx, NewClient(server.URL), "/ChatStream", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		received := make([]chatEvent, 0)
+		for event := range events {
+			received = append(received, *event.Payload)
+		}
+
+		expected := []chatEvent{userJoined{Name: "John"}, &messagePosted{Text: "hello"}}
+		if !reflect.DeepEqual(received, expected) {
+			t.Errorf("unexpected events, got %#v", received)
+		}
+	})
+
+	t.Run("delivers events of overridden type without payload", func(t *testing.T) {
+		router := NewRouter()
+		router.Register(Stream(func(ctx context.Context, _ *empty) (<-chan Event[chatEvent], error) {
+			c := make(chan Event[chatEvent], 1)
+			var payload chatEvent = userJoined{Name: "John"}
+			c <- Event[chatEvent]{ID: "1", Type: "joined", Payload: &payload}
+			close(c)
+			return c, nil
+		}, Name("CustomChatStream")))
+
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		events, err := Subscribe[empty, chatEvent](ctx, NewClient(server.URL), "/CustomChatStream", nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		event, ok := <-events
+		if !ok || event.ID != "1" || event.Type != "joined" || event.Payload != nil || event.Err != nil {
+			t.Errorf("unexpected event, got %+v", event)
+		}
+	})
+
+	t.Run("describes variants", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		ServiceDiscovery(router)(rr, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		var endpoints []endpoint
+		if err := json.NewDecoder(rr.Body).Decode(&endpoints); err != nil {
+			t.Fatalf("decode endpoints: %v", err)
+		}
+
+		for _, e := range endpoints {
+			if e.Path != "http://example.com/ChatStream" {
+				continue
+			}
+
+			if !reflect.DeepEqual(e.Events, []string{"userJoined", "messagePosted"}) {
+				t.Errorf("unexpected events, got %v", e.Events)
+			}
+
+			if len(e.Response.OneOf) != 2 || e.Response.OneOf[0].Ref != "#/$defs/userJoined" || e.Response.OneOf[1].Ref != "#/$defs/messagePosted" {
+				t.Errorf("unexpected response schema, got %+v", e.Response)
+			}
+
+			return
+		}
+
+		t.Errorf("endpoint not found, got %+v", endpoints)
+	})
+}